@@ -0,0 +1,187 @@
+package hexer
+
+import (
+	"context"
+)
+
+//
+// The file defines evaluator of basic graph pattern (BGP). The BGP is a set
+// of patterns sharing variables. It is evaluated using index nested-loop
+// joins: solutions of pattern are substituted into next pattern, which is
+// matched against the store.
+//
+
+// Join evaluates basic graph pattern against the store. Patterns are
+// re-ordered so that the most selective pattern is evaluated first.
+func Join(ctx context.Context, store Getter, bgp ...Pattern) (Bindings, error) {
+	if len(bgp) == 0 {
+		return &join{}, nil
+	}
+
+	seq := plan(bgp)
+
+	q, ok := seq[0].Bind(Binding{})
+	if !ok {
+		return &join{}, nil
+	}
+
+	stream, err := store.Match(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &join{
+		ctx:   ctx,
+		store: store,
+		seq:   seq,
+		stack: []frame{{binding: Binding{}, stream: stream}},
+	}, nil
+}
+
+// the state of nested-loop, the binding is used to produce the stream
+type frame struct {
+	binding Binding
+	stream  Stream
+}
+
+type join struct {
+	ctx   context.Context
+	store Getter
+	seq   []Pattern
+	stack []frame
+	head  Binding
+	err   error
+}
+
+func (join *join) Head() Binding {
+	return join.head
+}
+
+func (join *join) Next() bool {
+	for len(join.stack) > 0 {
+		depth := len(join.stack) - 1
+		top := join.stack[depth]
+
+		if !top.stream.Next() {
+			err := top.stream.Err()
+			top.stream.Close()
+			join.stack = join.stack[:depth]
+			if err != nil {
+				join.fail(err)
+				return false
+			}
+			continue
+		}
+
+		binding, ok := join.seq[depth].Solve(top.binding, top.stream.Head())
+		if !ok {
+			continue
+		}
+
+		if depth == len(join.seq)-1 {
+			join.head = binding
+			return true
+		}
+
+		q, ok := join.seq[depth+1].Bind(binding)
+		if !ok {
+			continue
+		}
+
+		stream, err := join.store.Match(join.ctx, q)
+		if err != nil {
+			join.fail(err)
+			return false
+		}
+
+		join.stack = append(join.stack, frame{binding: binding, stream: stream})
+	}
+
+	return false
+}
+
+func (join *join) FMap(f func(Binding) error) error {
+	for join.Next() {
+		if err := f(join.Head()); err != nil {
+			return err
+		}
+	}
+	return join.err
+}
+
+func (join *join) Err() error { return join.err }
+
+func (join *join) Close() error {
+	for _, top := range join.stack {
+		top.stream.Close()
+	}
+	join.stack = nil
+	return nil
+}
+
+// the failure of any nested stream terminates the join
+func (join *join) fail(err error) {
+	join.Close()
+	join.err = err
+}
+
+// orders patterns by selectivity, variables bound by earlier patterns
+// are matched exactly. Ties are resolved in favour of patterns connected
+// with earlier ones, otherwise the order of patterns is preserved.
+func plan(bgp []Pattern) []Pattern {
+	seq := make([]Pattern, 0, len(bgp))
+	use := make([]bool, len(bgp))
+	vars := map[string]bool{}
+
+	for len(seq) < len(bgp) {
+		at, best, link := -1, -1, false
+		for i, q := range bgp {
+			if use[i] {
+				continue
+			}
+
+			score, connected := selectivity(q, vars)
+			if score > best || (score == best && connected && !link) {
+				at, best, link = i, score, connected
+			}
+		}
+
+		use[at] = true
+		seq = append(seq, bgp[at])
+		for _, v := range bgp[at].Vars() {
+			vars[v] = true
+		}
+	}
+
+	return seq
+}
+
+// estimates selectivity of the pattern from hints of ⟨s,p,o⟩
+func selectivity(q Pattern, vars map[string]bool) (int, bool) {
+	hs, cs := hintOf(q.S, vars)
+	hp, cp := hintOf(q.P, vars)
+	ho, co := hintOf(q.O, vars)
+
+	return weightOf(hs) + weightOf(hp) + weightOf(ho), cs || cp || co
+}
+
+func hintOf[T any](pred *Predicate[T], vars map[string]bool) (Hint, bool) {
+	if pred != nil && pred.Var != "" && vars[pred.Var] {
+		return HINT_MATCH, true
+	}
+
+	return hintFor(pred), false
+}
+
+func weightOf(hint Hint) int {
+	switch hint {
+	case HINT_MATCH:
+		return 4
+	case HINT_FILTER_PREFIX:
+		return 2
+	case HINT_FILTER:
+		return 1
+	default:
+		return 0
+	}
+}
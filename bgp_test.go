@@ -0,0 +1,130 @@
+package hexer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+	"github.com/fogfish/it/v2"
+)
+
+const (
+	A = curie.IRI("u:A")
+	B = curie.IRI("u:B")
+	C = curie.IRI("u:C")
+	D = curie.IRI("u:D")
+)
+
+// in-memory store, the pattern is evaluated by filters over the bag
+type getter hexer.Bag
+
+func (bag getter) Match(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
+	stream := hexer.NewStream(hexer.Bag(bag))
+	stream = hexer.NewFilterS(q.HintForS, q.S, stream)
+	stream = hexer.NewFilterP(q.HintForP, q.P, stream)
+	stream = hexer.NewFilterO(q.HintForO, q.O, stream)
+	return stream, nil
+}
+
+type faulty struct{}
+
+func (faulty) Match(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
+	return nil, fmt.Errorf("faulty")
+}
+
+func TestJoin(t *testing.T) {
+	store := getter{
+		hexer.From(A, "follows", B),
+		hexer.From(B, "follows", C),
+		hexer.From(C, "follows", B),
+		hexer.From(B, "status", "b"),
+		hexer.From(D, "status", "d"),
+		hexer.From(A, "age", 20),
+		hexer.From(C, "score", 20),
+		hexer.From(D, "score", 5),
+	}
+
+	Seq := func(t *testing.T, bgp ...hexer.Pattern) it.SeqOf[hexer.Binding] {
+		t.Helper()
+		seq := []hexer.Binding{}
+		bindings, err := hexer.Join(context.Background(), store, bgp...)
+		it.Then(t).Should(it.Nil(err))
+		it.Then(t).Should(
+			it.Nil(bindings.FMap(func(b hexer.Binding) error { seq = append(seq, b); return nil })),
+		)
+
+		return it.Seq(seq)
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t).Equal(),
+		)
+	})
+
+	t.Run("Pattern", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t,
+				hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Eq(B)),
+			).Equal(
+				hexer.Binding{"x": xsd.AnyURI(A)},
+				hexer.Binding{"x": xsd.AnyURI(C)},
+			),
+		)
+	})
+
+	t.Run("Chain", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t,
+				hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("y")),
+				hexer.Query(hexer.IRI.Var("y"), hexer.IRI.Equal("status"), hexer.Var("s")),
+			).Equal(
+				hexer.Binding{"x": xsd.AnyURI(A), "y": xsd.AnyURI(B), "s": xsd.String("b")},
+				hexer.Binding{"x": xsd.AnyURI(C), "y": xsd.AnyURI(B), "s": xsd.String("b")},
+			),
+		)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t,
+				hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("y")),
+				hexer.Query(hexer.IRI.Var("y"), hexer.IRI.Equal("follows"), hexer.Var("x")),
+			).Equal(
+				hexer.Binding{"x": xsd.AnyURI(B), "y": xsd.AnyURI(C)},
+				hexer.Binding{"x": xsd.AnyURI(C), "y": xsd.AnyURI(B)},
+			),
+		)
+	})
+
+	t.Run("Constraint", func(t *testing.T) {
+		lt := &hexer.Predicate[xsd.Value]{Clause: hexer.LT, Value: xsd.Integer(10), Var: "x"}
+		gt := &hexer.Predicate[xsd.Value]{Clause: hexer.GT, Value: xsd.Integer(10), Var: "x"}
+
+		it.Then(t).Should(
+			Seq(t,
+				hexer.Query(hexer.IRI.Equal(A), hexer.IRI.Equal("age"), hexer.Var("x")),
+				hexer.Query(hexer.IRI.Var("t"), hexer.IRI.Equal("score"), lt),
+			).Equal(),
+			Seq(t,
+				hexer.Query(hexer.IRI.Equal(A), hexer.IRI.Equal("age"), hexer.Var("x")),
+				hexer.Query(hexer.IRI.Var("t"), hexer.IRI.Equal("score"), gt),
+			).Equal(
+				hexer.Binding{"t": xsd.AnyURI(C), "x": xsd.Integer(20)},
+			),
+		)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Error(
+				hexer.Join(context.Background(), faulty{},
+					hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("y")),
+				),
+			).Contain("faulty"),
+		)
+	})
+}
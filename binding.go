@@ -0,0 +1,269 @@
+package hexer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer/xsd"
+)
+
+//
+// The file defines variables and solutions of patterns. Variables ?x are
+// declared at ⟨s,p,o⟩ positions of pattern using IRI.Var and Var predicates.
+// The variable might be constrained by predicate (e.g. ?x < 10), it is
+// the predicate with both Clause and Var defined.
+// The solution is a binding of variables to values.
+//
+
+// Binding of variables to values, the solution of pattern.
+// IRIs are bound as xsd.AnyURI.
+type Binding map[string]xsd.Value
+
+// Vars returns sorted names of bound variables
+func (b Binding) Vars() []string {
+	seq := make([]string, 0, len(b))
+	for k := range b {
+		seq = append(seq, k)
+	}
+	sort.Strings(seq)
+	return seq
+}
+
+// IRI returns value of variable bound to IRI
+func (b Binding) IRI(name string) (curie.IRI, bool) {
+	v, ok := b[name].(xsd.AnyURI)
+	return curie.IRI(v), ok
+}
+
+// Project binding to the variables
+func (b Binding) Project(vars ...string) Binding {
+	val := make(Binding, len(vars))
+	for _, k := range vars {
+		if v, has := b[k]; has {
+			val[k] = v
+		}
+	}
+	return val
+}
+
+func (b Binding) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("{")
+	for i, k := range b.Vars() {
+		if i != 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(fmt.Sprintf("?%s: %v", k, b[k]))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// Bindings is a stream of solutions, it follows the contract of Stream
+type Bindings interface {
+	Head() Binding
+	Next() bool
+	FMap(func(Binding) error) error
+	Err() error
+	Close() error
+}
+
+// Vars returns variables declared by pattern
+func (q Pattern) Vars() []string {
+	seq := []string{}
+	if q.S != nil && q.S.Var != "" {
+		seq = append(seq, q.S.Var)
+	}
+	if q.P != nil && q.P.Var != "" {
+		seq = append(seq, q.P.Var)
+	}
+	if q.O != nil && q.O.Var != "" {
+		seq = append(seq, q.O.Var)
+	}
+	return seq
+}
+
+// Bind substitutes bound variables into pattern, unbound variables are
+// wildcards or constraints. It fails if value bound to variable cannot be used at
+// the position (e.g. literal at subject position) or it does not satisfy
+// the constraint of variable (e.g. ?x < 10).
+func (q Pattern) Bind(binding Binding) (Pattern, bool) {
+	s, ok := bindIRI(q.S, binding)
+	if !ok {
+		return q, false
+	}
+
+	p, ok := bindIRI(q.P, binding)
+	if !ok {
+		return q, false
+	}
+
+	o := q.O
+	if o != nil && o.Var != "" {
+		if v, has := binding[o.Var]; has {
+			if !holdsValue(o, v) {
+				return q, false
+			}
+			o = &Predicate[xsd.Value]{Clause: EQ, Value: v}
+		} else if o.Clause == VAR {
+			o = nil
+		}
+	}
+
+	return Query(s, p, o).InGraph(q.G), true
+}
+
+func bindIRI(pred *Predicate[curie.IRI], binding Binding) (*Predicate[curie.IRI], bool) {
+	if pred == nil || pred.Var == "" {
+		return pred, true
+	}
+
+	v, has := binding[pred.Var]
+	if !has && pred.Clause == VAR {
+		return nil, true
+	}
+	if !has {
+		return pred, true
+	}
+
+	iri, ok := v.(xsd.AnyURI)
+	if !ok || !holdsIRI(pred, curie.IRI(iri)) {
+		return nil, false
+	}
+
+	return &Predicate[curie.IRI]{Clause: EQ, Value: curie.IRI(iri)}, true
+}
+
+// checks value bound to variable against the constraint of variable
+func holdsValue(pred *Predicate[xsd.Value], v xsd.Value) bool {
+	switch pred.Clause {
+	case EQ:
+		return xsd.SameKind(v, pred.Value) && xsd.Compare(v, pred.Value) == 0
+	case PQ:
+		return xsd.HasPrefix(v, pred.Value)
+	case LT:
+		return xsd.SameKind(v, pred.Value) && xsd.Compare(v, pred.Value) == -1
+	case GT:
+		return xsd.SameKind(v, pred.Value) && xsd.Compare(v, pred.Value) == 1
+	case IN:
+		return xsd.Compare(v, pred.Value) >= 0 && xsd.Compare(v, pred.Other) <= 0
+	default:
+		return true
+	}
+}
+
+// checks IRI bound to variable against the constraint of variable
+func holdsIRI(pred *Predicate[curie.IRI], iri curie.IRI) bool {
+	switch pred.Clause {
+	case EQ:
+		return iri == pred.Value
+	case PQ:
+		return strings.HasPrefix(string(iri), string(pred.Value))
+	default:
+		return true
+	}
+}
+
+// Solve extends binding with values of statement matching the pattern.
+// It fails if statement conflicts with the binding, e.g. variable is used
+// twice within the pattern.
+func (q Pattern) Solve(binding Binding, spock SPOCK) (Binding, bool) {
+	ext := make(Binding, len(binding)+3)
+	for k, v := range binding {
+		ext[k] = v
+	}
+
+	if q.S != nil && q.S.Var != "" && !unify(ext, q.S.Var, xsd.AnyURI(spock.S)) {
+		return nil, false
+	}
+
+	if q.P != nil && q.P.Var != "" && !unify(ext, q.P.Var, xsd.AnyURI(spock.P)) {
+		return nil, false
+	}
+
+	if q.O != nil && q.O.Var != "" && !unify(ext, q.O.Var, spock.O) {
+		return nil, false
+	}
+
+	return ext, true
+}
+
+func unify(binding Binding, name string, value xsd.Value) bool {
+	if v, has := binding[name]; has {
+		return xsd.SameKind(v, value) && xsd.Compare(v, value) == 0
+	}
+
+	binding[name] = value
+	return true
+}
+
+//------------------------------------------------------------------------------
+
+type solutions struct {
+	q      Pattern
+	head   Binding
+	stream Stream
+}
+
+// Solve transforms stream of statements matching the pattern into
+// the stream of solutions.
+func Solve(q Pattern, stream Stream) Bindings {
+	return &solutions{q: q, stream: stream}
+}
+
+func (seq *solutions) Head() Binding {
+	return seq.head
+}
+
+func (seq *solutions) Next() bool {
+	for seq.stream.Next() {
+		if binding, ok := seq.q.Solve(Binding{}, seq.stream.Head()); ok {
+			seq.head = binding
+			return true
+		}
+	}
+	return false
+}
+
+func (seq *solutions) FMap(f func(Binding) error) error {
+	return seq.stream.FMap(func(spock SPOCK) error {
+		if binding, ok := seq.q.Solve(Binding{}, spock); ok {
+			return f(binding)
+		}
+		return nil
+	})
+}
+
+func (seq *solutions) Err() error { return seq.stream.Err() }
+
+func (seq *solutions) Close() error { return seq.stream.Close() }
+
+//------------------------------------------------------------------------------
+
+type projection struct {
+	vars []string
+	seq  Bindings
+}
+
+// Project stream of solutions to the variables
+func Project(seq Bindings, vars ...string) Bindings {
+	return &projection{vars: vars, seq: seq}
+}
+
+func (p *projection) Head() Binding {
+	return p.seq.Head().Project(p.vars...)
+}
+
+func (p *projection) Next() bool {
+	return p.seq.Next()
+}
+
+func (p *projection) FMap(f func(Binding) error) error {
+	return p.seq.FMap(func(b Binding) error { return f(b.Project(p.vars...)) })
+}
+
+func (p *projection) Err() error { return p.seq.Err() }
+
+func (p *projection) Close() error { return p.seq.Close() }
@@ -0,0 +1,159 @@
+package hexer_test
+
+import (
+	"testing"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+	"github.com/fogfish/it/v2"
+)
+
+func TestBinding(t *testing.T) {
+	binding := hexer.Binding{"x": xsd.AnyURI(A), "y": xsd.Integer(10)}
+
+	t.Run("Vars", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Seq(binding.Vars()).Equal("x", "y"),
+		)
+	})
+
+	t.Run("IRI", func(t *testing.T) {
+		x, okx := binding.IRI("x")
+		_, oky := binding.IRI("y")
+
+		it.Then(t).Should(
+			it.True(okx),
+			it.Equal(x, A),
+			it.True(!oky),
+		)
+	})
+
+	t.Run("Project", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equiv(binding.Project("y", "z"), hexer.Binding{"y": xsd.Integer(10)}),
+		)
+	})
+
+	t.Run("String", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(binding.String(), "{?x: [u:A], ?y: 10}"),
+		)
+	})
+}
+
+func TestBind(t *testing.T) {
+	binding := hexer.Binding{"x": xsd.AnyURI(A), "y": xsd.Integer(20)}
+
+	Bind := func(t *testing.T, q hexer.Pattern) (string, bool) {
+		t.Helper()
+		q, ok := q.Bind(binding)
+		return q.Dump(), ok
+	}
+
+	t.Run("Substitute", func(t *testing.T) {
+		q, ok := Bind(t,
+			hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("age"), hexer.Var("y")),
+		)
+
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(q,
+				hexer.Query(hexer.IRI.Equal(A), hexer.IRI.Equal("age"), hexer.Eq(20)).Dump(),
+			),
+		)
+	})
+
+	t.Run("Wildcard", func(t *testing.T) {
+		q, ok := Bind(t,
+			hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Var("p"), hexer.Var("z")),
+		)
+
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(q,
+				hexer.Query(hexer.IRI.Equal(A), nil, nil).Dump(),
+			),
+		)
+	})
+
+	t.Run("LiteralAtSubject", func(t *testing.T) {
+		_, ok := Bind(t,
+			hexer.Query(hexer.IRI.Var("y"), hexer.IRI.Equal("age"), nil),
+		)
+
+		it.Then(t).Should(
+			it.True(!ok),
+		)
+	})
+
+	t.Run("Constraint", func(t *testing.T) {
+		Lt := func(v int) *hexer.Predicate[xsd.Value] {
+			return &hexer.Predicate[xsd.Value]{Clause: hexer.LT, Value: xsd.Integer(v), Var: "y"}
+		}
+		In := func(a, b int) *hexer.Predicate[xsd.Value] {
+			return &hexer.Predicate[xsd.Value]{Clause: hexer.IN, Value: xsd.Integer(a), Other: xsd.Integer(b), Var: "y"}
+		}
+
+		q, ok := Bind(t, hexer.Query(nil, hexer.IRI.Equal("score"), Lt(30)))
+		_, ltFailed := Bind(t, hexer.Query(nil, hexer.IRI.Equal("score"), Lt(10)))
+		_, inFailed := Bind(t, hexer.Query(nil, hexer.IRI.Equal("score"), In(30, 40)))
+
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(q,
+				hexer.Query(nil, hexer.IRI.Equal("score"), hexer.Eq(20)).Dump(),
+			),
+			it.True(!ltFailed),
+			it.True(!inFailed),
+		)
+	})
+
+	t.Run("ConstraintIRI", func(t *testing.T) {
+		Prefix := func(prefix curie.IRI) *hexer.Predicate[curie.IRI] {
+			return &hexer.Predicate[curie.IRI]{Clause: hexer.PQ, Value: prefix, Var: "x"}
+		}
+
+		q, ok := Bind(t, hexer.Query(Prefix("u:"), nil, nil))
+		_, failed := Bind(t, hexer.Query(Prefix("s:"), nil, nil))
+
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(q, hexer.Query(hexer.IRI.Equal(A), nil, nil).Dump()),
+			it.True(!failed),
+		)
+	})
+}
+
+func TestSolve(t *testing.T) {
+	q := hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("y"))
+
+	t.Run("Extend", func(t *testing.T) {
+		binding, ok := q.Solve(hexer.Binding{"z": xsd.Integer(1)}, hexer.From(A, "follows", B))
+
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equiv(binding, hexer.Binding{"x": xsd.AnyURI(A), "y": xsd.AnyURI(B), "z": xsd.Integer(1)}),
+		)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		_, ok := q.Solve(hexer.Binding{"x": xsd.AnyURI(C)}, hexer.From(A, "follows", B))
+
+		it.Then(t).Should(
+			it.True(!ok),
+		)
+	})
+
+	t.Run("SameVariable", func(t *testing.T) {
+		loop := hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("x"))
+		_, ok := loop.Solve(hexer.Binding{}, hexer.From(A, "follows", B))
+		binding, self := loop.Solve(hexer.Binding{}, hexer.From(A, "follows", A))
+
+		it.Then(t).Should(
+			it.True(!ok),
+			it.True(self),
+			it.Equiv(binding, hexer.Binding{"x": xsd.AnyURI(A)}),
+		)
+	})
+}
@@ -9,12 +9,14 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
 	"github.com/fogfish/curie"
 	"github.com/fogfish/guid/v2"
 	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
 )
 
 type Bag hexer.Bag
@@ -22,7 +24,9 @@ type Bag hexer.Bag
 func (bag *Bag) UnmarshalJSON(b []byte) error {
 	var raw any
 
-	if err := json.Unmarshal(b, &raw); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
 		return err
 	}
 
@@ -39,9 +43,13 @@ func (bag *Bag) UnmarshalJSON(b []byte) error {
 func decodeArray(bag *Bag, s, p *curie.IRI, seq []any) error {
 	for _, val := range seq {
 		switch o := val.(type) {
-		case float64:
+		case json.Number:
 			if s != nil && p != nil {
-				*bag = append(*bag, hexer.From(*s, *p, o))
+				v, err := number(o)
+				if err != nil {
+					return err
+				}
+				*bag = append(*bag, hexer.SPOCK{S: *s, P: *p, O: v})
 			}
 		case string:
 			if s != nil && p != nil {
@@ -98,8 +106,12 @@ func decodeObjectProperties(bag *Bag, s curie.IRI, obj map[string]any) error {
 		p := curie.IRI(key)
 
 		switch o := val.(type) {
-		case float64:
-			*bag = append(*bag, hexer.From(s, p, o))
+		case json.Number:
+			v, err := number(o)
+			if err != nil {
+				return err
+			}
+			*bag = append(*bag, hexer.SPOCK{S: s, P: p, O: v})
 		case string:
 			*bag = append(*bag, hexer.From(s, p, o))
 		case bool:
@@ -119,3 +131,17 @@ func decodeObjectProperties(bag *Bag, s curie.IRI, obj map[string]any) error {
 
 	return nil
 }
+
+// whole numbers are integers, other numbers are doubles
+func number(n json.Number) (xsd.Value, error) {
+	if v, err := n.Int64(); err == nil {
+		return xsd.Integer(v), nil
+	}
+
+	v, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("json number codec do not support %v", n)
+	}
+
+	return xsd.Double(v), nil
+}
@@ -0,0 +1,296 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package jsonld
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// max depth of nested remote contexts and term definitions
+const maxDepth = 16
+
+// term definition of the context
+type term struct {
+	id        string // IRI, compact IRI, term or keyword
+	typ       string // type coercion: @id, @vocab or datatype
+	lang      string // default language of term
+	hasLang   bool   // language is defined by term, it might be null
+	container string // @list, @set or @language
+	context   any    // scoped context
+}
+
+// the active context of JSON-LD document
+type context struct {
+	base     string
+	vocab    string
+	lang     string
+	terms    map[string]*term
+	prefixes []prefix
+}
+
+// term used as prefix to compact IRIs
+type prefix struct{ name, iri string }
+
+func (ctx *context) clone() *context {
+	terms := make(map[string]*term, len(ctx.terms))
+	for k, v := range ctx.terms {
+		terms[k] = v
+	}
+
+	return &context{
+		base:  ctx.base,
+		vocab: ctx.vocab,
+		lang:  ctx.lang,
+		terms: terms,
+	}
+}
+
+// local context is merged with the active one, the active one is not changed
+func (dec *decoder) local(ctx *context, raw any, depth int) (*context, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("json-ld context is too deep")
+	}
+
+	switch v := raw.(type) {
+	case nil:
+		return dec.initial(), nil
+	case []any:
+		for _, x := range v {
+			var err error
+			if ctx, err = dec.local(ctx, x, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return ctx, nil
+	case string:
+		return dec.remote(ctx, v, depth)
+	case object:
+		return dec.define(ctx, v)
+	default:
+		return nil, fmt.Errorf("json-ld context codec do not support %T (%v)", raw, raw)
+	}
+}
+
+func (dec *decoder) remote(ctx *context, iri string, depth int) (*context, error) {
+	iri = ctx.resolve(iri)
+
+	doc, has := dec.contexts[iri]
+	if !has {
+		if dec.loader == nil {
+			return nil, fmt.Errorf("json-ld remote context %s is not supported, see WithLoader", iri)
+		}
+
+		b, err := dec.loader(iri)
+		if err != nil {
+			return nil, fmt.Errorf("json-ld remote context %s: %w", iri, err)
+		}
+
+		val, err := parse(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("json-ld remote context %s: %w", iri, err)
+		}
+
+		obj, ok := val.(object)
+		if !ok {
+			return nil, fmt.Errorf("json-ld remote context %s is not object", iri)
+		}
+
+		doc, _ = obj.get("@context")
+		dec.contexts[iri] = doc
+	}
+
+	return dec.local(ctx, doc, depth+1)
+}
+
+func (dec *decoder) define(active *context, obj object) (*context, error) {
+	ctx := active.clone()
+
+	for _, m := range obj {
+		switch m.key {
+		case "@base":
+			switch v := m.val.(type) {
+			case nil:
+				ctx.base = ""
+			case string:
+				ctx.base = ctx.resolve(v)
+			default:
+				return nil, fmt.Errorf("json-ld @base codec do not support %T (%v)", m.val, m.val)
+			}
+		case "@vocab":
+			switch v := m.val.(type) {
+			case nil:
+				ctx.vocab = ""
+			case string:
+				ctx.vocab = ctx.expand(v, true)
+			default:
+				return nil, fmt.Errorf("json-ld @vocab codec do not support %T (%v)", m.val, m.val)
+			}
+		case "@language":
+			switch v := m.val.(type) {
+			case nil:
+				ctx.lang = ""
+			case string:
+				ctx.lang = v
+			default:
+				return nil, fmt.Errorf("json-ld @language codec do not support %T (%v)", m.val, m.val)
+			}
+		case "@version", "@protected", "@propagate", "@import", "@direction":
+			// processing hints are not supported
+		default:
+			switch v := m.val.(type) {
+			case nil:
+				delete(ctx.terms, m.key)
+			case string:
+				ctx.terms[m.key] = &term{id: v}
+			case object:
+				ctx.terms[m.key] = defineTerm(v)
+			default:
+				return nil, fmt.Errorf("json-ld term %s codec do not support %T (%v)", m.key, m.val, m.val)
+			}
+		}
+	}
+
+	return ctx, nil
+}
+
+func defineTerm(obj object) *term {
+	t := &term{}
+	for _, m := range obj {
+		switch m.key {
+		case "@id":
+			t.id, _ = m.val.(string)
+		case "@type":
+			t.typ, _ = m.val.(string)
+		case "@language":
+			t.lang, _ = m.val.(string)
+			t.hasLang = true
+		case "@container":
+			switch v := m.val.(type) {
+			case string:
+				t.container = v
+			case []any:
+				// @set is default behavior, other containers are exclusive
+				for _, x := range v {
+					if s, ok := x.(string); ok && s != "@set" {
+						t.container = s
+					}
+				}
+			}
+		case "@context":
+			t.context = m.val
+		}
+	}
+
+	return t
+}
+
+// expands term, compact IRI or relative IRI to IRI. The vocab flag defines
+// vocabulary-relative IRIs (e.g. properties, types) or document-relative
+// ones (e.g. @id). Terms which are not defined by context are kept as is.
+func (ctx *context) expand(value string, vocab bool) string {
+	return ctx.expandN(value, vocab, 0)
+}
+
+func (ctx *context) expandN(value string, vocab bool, depth int) string {
+	if depth > maxDepth || strings.HasPrefix(value, "@") {
+		return value
+	}
+
+	if vocab {
+		if t, has := ctx.terms[value]; has && t.id != "" && t.id != value {
+			return ctx.expandN(t.id, true, depth+1)
+		}
+	}
+
+	if at := strings.IndexByte(value, ':'); at != -1 {
+		prefix, suffix := value[:at], value[at+1:]
+		if prefix == "_" || strings.HasPrefix(suffix, "//") {
+			return value
+		}
+
+		if t, has := ctx.terms[prefix]; has && t.id != "" {
+			return ctx.expandN(t.id, true, depth+1) + suffix
+		}
+
+		return value
+	}
+
+	if vocab && ctx.vocab != "" {
+		return ctx.vocab + value
+	}
+
+	if !vocab {
+		return ctx.resolve(value)
+	}
+
+	return value
+}
+
+func (ctx *context) resolve(iri string) string {
+	if ctx.base == "" {
+		return iri
+	}
+
+	base, err := url.Parse(ctx.base)
+	if err != nil {
+		return iri
+	}
+
+	ref, err := url.Parse(iri)
+	if err != nil {
+		return iri
+	}
+
+	return base.ResolveReference(ref).String()
+}
+
+// terms which are used to compact IRIs, longest IRI first
+func (ctx *context) prefixesOf() []prefix {
+	if ctx.prefixes != nil {
+		return ctx.prefixes
+	}
+
+	seq := []prefix{}
+	for name, t := range ctx.terms {
+		if t.id == "" || strings.ContainsAny(name, ":@") {
+			continue
+		}
+
+		iri := ctx.expand(name, true)
+		if strings.HasSuffix(iri, "/") || strings.HasSuffix(iri, "#") {
+			seq = append(seq, prefix{name: name, iri: iri})
+		}
+	}
+
+	sortPrefixes(seq)
+	ctx.prefixes = seq
+	return seq
+}
+
+func sortPrefixes(seq []prefix) {
+	sort.Slice(seq, func(i, j int) bool {
+		if len(seq[i].iri) != len(seq[j].iri) {
+			return len(seq[i].iri) > len(seq[j].iri)
+		}
+		return seq[i].name < seq[j].name
+	})
+}
+
+func compact(seq []prefix, iri string) (string, bool) {
+	for _, p := range seq {
+		if strings.HasPrefix(iri, p.iri) {
+			return p.name + ":" + iri[len(p.iri):], true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,151 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package jsonld
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+// Encoder of JSON-LD document. Statements of same subject are written as
+// compacted node object if they are adjacent in the stream, e.g. stream of
+// SPO index. IRIs are kept compact, the namespaces are declared in @context.
+// References to other nodes are {"@id": ...} objects, rdf:type is @type.
+// Statements of named graphs are written as {"@id": g, "@graph": [...]}.
+type Encoder struct {
+	w  *bufio.Writer
+	ns curie.Namespaces
+}
+
+// Create new encoder writing JSON-LD document to the stream
+func NewEncoder(w io.Writer, ns curie.Namespaces) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w), ns: ns}
+}
+
+// Encode all statements of the stream as JSON-LD document
+func (enc *Encoder) Encode(stream hexer.Stream) error {
+	enc.w.WriteString("{")
+	if len(enc.ns) > 0 {
+		context, err := json.Marshal(enc.ns)
+		if err != nil {
+			return err
+		}
+		enc.w.WriteString(`"@context":`)
+		enc.w.Write(context)
+		enc.w.WriteString(",")
+	}
+	enc.w.WriteString(`"@graph":[`)
+
+	var g, s curie.IRI
+	var node map[string]any
+	seq := 0
+
+	flush := func() error {
+		if node == nil {
+			return nil
+		}
+
+		var obj any = node
+		if g != "" {
+			obj = map[string]any{"@id": string(g), "@graph": []any{node}}
+		}
+
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+
+		if seq > 0 {
+			enc.w.WriteString(",")
+		}
+		enc.w.WriteString("\n  ")
+		enc.w.Write(b)
+		seq++
+		return nil
+	}
+
+	err := stream.FMap(func(spock hexer.SPOCK) error {
+		if node == nil || g != spock.G || s != spock.S {
+			if err := flush(); err != nil {
+				return err
+			}
+			g, s, node = spock.G, spock.S, map[string]any{"@id": string(spock.S)}
+		}
+
+		if spock.P == rdfType {
+			if iri, ok := spock.O.(xsd.AnyURI); ok {
+				appendValue(node, "@type", string(iri))
+				return nil
+			}
+		}
+
+		val, err := encodeValue(spock.O)
+		if err != nil {
+			return err
+		}
+
+		appendValue(node, string(spock.P), val)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if seq > 0 {
+		enc.w.WriteString("\n")
+	}
+	enc.w.WriteString("]}\n")
+
+	return enc.w.Flush()
+}
+
+// single value is written as is, multiple values as array
+func appendValue(node map[string]any, key string, val any) {
+	switch seq := node[key].(type) {
+	case nil:
+		node[key] = val
+	case []any:
+		node[key] = append(seq, val)
+	default:
+		node[key] = []any{seq, val}
+	}
+}
+
+// value object, native JSON values are used for strings, integers and booleans.
+// IRIs are plain strings, encoding/json writes curie.IRI as safe CURIE.
+func encodeValue(o xsd.Value) (any, error) {
+	switch v := o.(type) {
+	case xsd.AnyURI:
+		return map[string]any{"@id": string(v)}, nil
+	case xsd.String:
+		return string(v), nil
+	case xsd.Integer:
+		return int64(v), nil
+	case xsd.Boolean:
+		return bool(v), nil
+	case xsd.LangString:
+		return map[string]any{"@value": v.Value, "@language": v.Lang}, nil
+	}
+
+	lexical, err := xsd.Lexical(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"@value": lexical, "@type": string(o.XSDType())}, nil
+}
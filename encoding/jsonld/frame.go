@@ -0,0 +1,198 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+// Frame defines the shape of JSON-LD document: root nodes and embedding of
+// linked nodes. Roots are either the subject, instances of the type or
+// nodes which are not linked by other nodes if frame is empty.
+type Frame struct {
+	Subject curie.IRI // the root node
+	Type    curie.IRI // root nodes are instances of the type
+	Depth   int       // max depth of embedded nodes, 0 disables embedding
+}
+
+// FrameEncoder writes statements as nested JSON-LD document, the linked
+// nodes are embedded into the object of the node up to the frame depth.
+// Each node is embedded once, other links are written as references.
+// Statements are kept in memory to build the document, the graph of
+// statements is ignored. Blank nodes embedded into single node are written
+// without @id, so that the document is decoded into same statements (up to
+// blank node identity).
+type FrameEncoder struct {
+	w     io.Writer
+	ns    curie.Namespaces
+	frame Frame
+}
+
+// Create new encoder writing framed JSON-LD document to the stream
+func NewFrameEncoder(w io.Writer, ns curie.Namespaces, frame Frame) *FrameEncoder {
+	return &FrameEncoder{w: w, ns: ns, frame: frame}
+}
+
+// Encode all statements of the stream as framed JSON-LD document. The
+// document is the node object if frame defines subject, it contains
+// the subject and nodes embedded into it. Otherwise, root nodes followed
+// by nodes, which are not embedded, are written into @graph.
+func (enc *FrameEncoder) Encode(stream hexer.Stream) error {
+	g := &frameGraph{
+		frame: enc.frame,
+		nodes: map[curie.IRI][]hexer.SPOCK{},
+		refs:  map[curie.IRI]int{},
+		done:  map[curie.IRI]struct{}{},
+	}
+
+	err := stream.FMap(func(spock hexer.SPOCK) error {
+		if _, has := g.nodes[spock.S]; !has {
+			g.order = append(g.order, spock.S)
+		}
+		g.nodes[spock.S] = append(g.nodes[spock.S], spock)
+
+		if iri, ok := spock.O.(xsd.AnyURI); ok && spock.P != rdfType {
+			g.refs[curie.IRI(iri)]++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var doc map[string]any
+	if enc.frame.Subject != "" {
+		if _, has := g.nodes[enc.frame.Subject]; !has {
+			return fmt.Errorf("json-ld frame subject %s is not found", enc.frame.Subject)
+		}
+
+		if doc, err = g.embed(enc.frame.Subject, 0); err != nil {
+			return err
+		}
+	} else {
+		seq := []any{}
+		for _, s := range append(g.roots(), g.order...) {
+			if _, has := g.done[s]; has {
+				continue
+			}
+
+			node, err := g.embed(s, 0)
+			if err != nil {
+				return err
+			}
+			seq = append(seq, node)
+		}
+		doc = map[string]any{"@graph": seq}
+	}
+
+	if len(enc.ns) > 0 {
+		doc["@context"] = enc.ns
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	_, err = enc.w.Write(append(b, '\n'))
+	return err
+}
+
+// statements of nodes, in the order of the stream
+type frameGraph struct {
+	frame Frame
+	nodes map[curie.IRI][]hexer.SPOCK
+	order []curie.IRI
+	refs  map[curie.IRI]int      // number of links to the node
+	done  map[curie.IRI]struct{} // nodes written into the document
+}
+
+func (g *frameGraph) roots() []curie.IRI {
+	seq := []curie.IRI{}
+
+	if g.frame.Type != "" {
+		for _, s := range g.order {
+			for _, spock := range g.nodes[s] {
+				if spock.P == rdfType && xsd.Compare(spock.O, xsd.AnyURI(g.frame.Type)) == 0 {
+					seq = append(seq, s)
+					break
+				}
+			}
+		}
+		return seq
+	}
+
+	linked := map[curie.IRI]struct{}{}
+	for _, s := range g.order {
+		for _, spock := range g.nodes[s] {
+			if iri, ok := spock.O.(xsd.AnyURI); ok && curie.IRI(iri) != s {
+				linked[curie.IRI(iri)] = struct{}{}
+			}
+		}
+	}
+
+	for _, s := range g.order {
+		if _, has := linked[s]; !has {
+			seq = append(seq, s)
+		}
+	}
+
+	return seq
+}
+
+// embeds node object, the node is written once
+func (g *frameGraph) embed(s curie.IRI, depth int) (map[string]any, error) {
+	node := map[string]any{}
+	if depth == 0 || !strings.HasPrefix(string(s), "_:") || g.refs[s] > 1 {
+		node["@id"] = string(s)
+	}
+
+	g.done[s] = struct{}{}
+	for _, spock := range g.nodes[s] {
+		iri, isIRI := spock.O.(xsd.AnyURI)
+
+		if spock.P == rdfType && isIRI {
+			appendValue(node, "@type", string(iri))
+			continue
+		}
+
+		if isIRI && g.embeddable(curie.IRI(iri), depth) {
+			obj, err := g.embed(curie.IRI(iri), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			appendValue(node, string(spock.P), obj)
+			continue
+		}
+
+		val, err := encodeValue(spock.O)
+		if err != nil {
+			return nil, err
+		}
+		appendValue(node, string(spock.P), val)
+	}
+
+	return node, nil
+}
+
+func (g *frameGraph) embeddable(s curie.IRI, depth int) bool {
+	if _, has := g.nodes[s]; !has || depth >= g.frame.Depth {
+		return false
+	}
+
+	_, done := g.done[s]
+	return !done
+}
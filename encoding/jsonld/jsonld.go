@@ -6,91 +6,247 @@
 // https://github.com/fogfish/hexagon
 //
 
+// Package jsonld implements JSON-LD codec of knowledge statements.
+//
+// The decoder processes @context: terms, compact IRIs, @vocab, @base and
+// @language are expanded, type coercion (@id, @vocab, data types) and
+// containers (@list, @set, @language) are applied to values. Remote contexts
+// are loaded only if the decoder is configured WithLoader. Expanded IRIs are compacted using namespaces
+// supplied by the caller and prefixes defined by the context, otherwise
+// they are kept absolute. Keys, which are not defined by the context,
+// are used as predicates as is. @type is decoded as rdf:type statements.
 package jsonld
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fogfish/curie"
 	"github.com/fogfish/guid/v2"
 	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+const xmlSchema = "http://www.w3.org/2001/XMLSchema#"
+
+const (
+	rdfType  = curie.IRI("rdf:type")
+	rdfFirst = curie.IRI("rdf:first")
+	rdfRest  = curie.IRI("rdf:rest")
+	rdfNil   = curie.IRI("rdf:nil")
 )
 
 type Bag hexer.Bag
 
 func (bag *Bag) UnmarshalJSON(b []byte) error {
-	var raw any
+	return NewDecoder(bytes.NewReader(b)).Decode(bag)
+}
+
+// Loader of remote context, it returns JSON-LD document defining @context
+type Loader func(iri string) ([]byte, error)
+
+// limits of remote context loaded over HTTP
+const (
+	httpLoaderTimeout = 10 * time.Second
+	httpLoaderMaxSize = 1 << 20
+)
+
+// NewHTTPLoader creates loader of remote contexts using HTTP client.
+// The client without timeout is limited to 10 seconds, the context is
+// limited to 1 MiB. The loader fetches any IRI given by the document,
+// the client should restrict hosts if documents are not trusted.
+func NewHTTPLoader(client *http.Client) Loader {
+	if client.Timeout == 0 {
+		c := *client
+		c.Timeout = httpLoaderTimeout
+		client = &c
+	}
+
+	return func(iri string) ([]byte, error) {
+		req, err := http.NewRequest(http.MethodGet, iri, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/ld+json, application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("http status %d", resp.StatusCode)
+		}
+
+		b, err := io.ReadAll(io.LimitReader(resp.Body, httpLoaderMaxSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > httpLoaderMaxSize {
+			return nil, fmt.Errorf("context exceeds %d bytes", httpLoaderMaxSize)
+		}
+
+		return b, nil
+	}
+}
+
+// Decoder of JSON-LD document
+type Decoder struct {
+	r      io.Reader
+	base   string
+	ns     curie.Namespaces
+	loader Loader
+}
 
-	if err := json.Unmarshal(b, &raw); err != nil {
+// Option of the decoder
+type Option func(*Decoder)
+
+// WithNamespaces configures prefixes used to compact absolute IRIs
+func WithNamespaces(ns curie.Namespaces) Option {
+	return func(dec *Decoder) { dec.ns = ns }
+}
+
+// WithBase configures the base IRI used to resolve relative IRIs
+func WithBase(base string) Option {
+	return func(dec *Decoder) { dec.base = base }
+}
+
+// WithLoader configures loader of remote contexts (e.g. NewHTTPLoader).
+// Remote contexts are not supported by default.
+func WithLoader(loader Loader) Option {
+	return func(dec *Decoder) { dec.loader = loader }
+}
+
+// Create new decoder reading JSON-LD document from the stream
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	dec := &Decoder{r: r}
+
+	for _, opt := range opts {
+		opt(dec)
+	}
+
+	return dec
+}
+
+// Decode JSON-LD document into the bag
+func (dec *Decoder) Decode(bag *Bag) error {
+	val, err := parse(dec.r)
+	if err != nil {
 		return err
 	}
 
-	switch val := raw.(type) {
-	case []any:
-		return decodeArray(bag, nil, nil, val)
-	case map[string]any:
-		graph, has := val["@graph"]
-		if has {
-			switch seq := graph.(type) {
-			case []any:
-				return decodeArray(bag, nil, nil, seq)
-			default:
-				return fmt.Errorf("json-ld graph codec do not support %T (%v)", val, val)
-			}
+	ns := make([]prefix, 0, len(dec.ns))
+	for name, iri := range dec.ns {
+		ns = append(ns, prefix{name: name, iri: iri})
+	}
+	sortPrefixes(ns)
+
+	codec := &decoder{
+		bag:      bag,
+		base:     dec.base,
+		ns:       ns,
+		loader:   dec.loader,
+		contexts: map[string]any{},
+		blanks:   map[string]curie.IRI{},
+	}
+
+	return codec.document(val)
+}
+
+//------------------------------------------------------------------------------
+
+type decoder struct {
+	bag      *Bag
+	base     string
+	ns       []prefix
+	loader   Loader
+	contexts map[string]any
+	blanks   map[string]curie.IRI
+}
+
+func (dec *decoder) initial() *context {
+	return &context{base: dec.base, terms: map[string]*term{}}
+}
+
+func (dec *decoder) emit(g, s, p curie.IRI, o xsd.Value) {
+	*dec.bag = append(*dec.bag, hexer.SPOCK{G: g, S: s, P: p, O: o})
+}
+
+// IRI of the document, blank nodes are replaced with unique identities
+func (dec *decoder) iri(ctx *context, value string, vocab bool) curie.IRI {
+	iri := ctx.expand(value, vocab)
+	if strings.HasPrefix(iri, "_:") {
+		return dec.blank(iri[2:])
+	}
+
+	if s, ok := compact(dec.ns, iri); ok {
+		return curie.IRI(s)
+	}
+
+	if s, ok := compact(ctx.prefixesOf(), iri); ok {
+		return curie.IRI(s)
+	}
+
+	return curie.IRI(iri)
+}
+
+func (dec *decoder) blank(label string) curie.IRI {
+	id, has := dec.blanks[label]
+	if !has {
+		id = anonymous()
+		dec.blanks[label] = id
+	}
+	return id
+}
+
+func anonymous() curie.IRI {
+	return curie.New("_:%s", guid.L(guid.Clock))
+}
+
+// keywords of object, including aliases defined by context
+func keywords(ctx *context, obj object) map[string]any {
+	kw := map[string]any{}
+	for _, m := range obj {
+		if key := ctx.expand(m.key, true); strings.HasPrefix(key, "@") {
+			kw[key] = m.val
 		}
-		return decodeObject(bag, nil, nil, val)
+	}
+	return kw
+}
+
+func (dec *decoder) document(val any) error {
+	ctx := dec.initial()
+
+	switch v := val.(type) {
+	case []any:
+		return dec.nodes(ctx, "", v)
+	case object:
+		_, err := dec.node(ctx, "", v)
+		return err
 	default:
 		return fmt.Errorf("json-ld codec do not support %T (%v)", val, val)
 	}
 }
 
-// type Node = map[string]any
-
-// // Unmarshal JSON-LD into the store
-// func Unmarshal(data []byte, store *hexagon.Store) error {
-// 	var bag any
-
-// 	if err := json.Unmarshal(data, &bag); err != nil {
-// 		return err
-// 	}
-
-// 	switch val := bag.(type) {
-// 	case []any:
-// 		return decodeArray(store, nil, nil, val)
-// 	case Node:
-// 		graph, has := val["@graph"]
-// 		if has {
-// 			switch seq := graph.(type) {
-// 			case []any:
-// 				return decodeArray(store, nil, nil, seq)
-// 			default:
-// 				return fmt.Errorf("json-ld graph codec do not support %T (%v)", val, val)
-// 			}
-// 		}
-// 		return decodeObject(store, nil, nil, val)
-// 	default:
-// 		return fmt.Errorf("json-ld codec do not support %T (%v)", val, val)
-// 	}
-// }
-
-func decodeArray(bag *Bag, s, p *curie.IRI, seq []any) error {
+func (dec *decoder) nodes(ctx *context, g curie.IRI, seq []any) error {
 	for _, val := range seq {
-		switch o := val.(type) {
-		case float64:
-			if s != nil && p != nil {
-				*bag = append(*bag, hexer.From(*s, *p, o))
-			}
-		case string:
-			if s != nil && p != nil {
-				*bag = append(*bag, hexer.From(*s, *p, o))
+		switch v := val.(type) {
+		case object:
+			if _, err := dec.node(ctx, g, v); err != nil {
+				return err
 			}
-		case bool:
-			if s != nil && p != nil {
-				*bag = append(*bag, hexer.From(*s, *p, o))
+		case []any:
+			if err := dec.nodes(ctx, g, v); err != nil {
+				return err
 			}
-		case map[string]any:
-			decodeObject(bag, s, p, o)
+		case nil:
 		default:
 			return fmt.Errorf("json-ld array codec do not support %T (%v)", val, val)
 		}
@@ -98,108 +254,330 @@ func decodeArray(bag *Bag, s, p *curie.IRI, seq []any) error {
 	return nil
 }
 
-func decodeObject(bag *Bag, s, p *curie.IRI, obj map[string]any) error {
-	id, has := decodeObjectID(obj)
-	if !has {
-		id = curie.New("_:%s", guid.L(guid.Clock))
+func (dec *decoder) node(ctx *context, g curie.IRI, obj object) (curie.IRI, error) {
+	ctx, err := dec.scope(ctx, obj)
+	if err != nil {
+		return "", err
 	}
 
-	if s != nil && p != nil {
-		*bag = append(*bag, hexer.From(*s, *p, id))
+	s, err := dec.subject(ctx, obj)
+	if err != nil {
+		return "", err
 	}
 
-	return decodeObjectProperties(bag, id, obj)
+	return s, dec.properties(ctx, g, s, obj)
+}
+
+// applies embedded context of object
+func (dec *decoder) scope(ctx *context, obj object) (*context, error) {
+	for _, m := range obj {
+		if m.key == "@context" {
+			return dec.local(ctx, m.val, 0)
+		}
+	}
+	return ctx, nil
 }
 
-func decodeObjectID(obj map[string]any) (curie.IRI, bool) {
-	raw, has := obj["@id"]
+func (dec *decoder) subject(ctx *context, obj object) (curie.IRI, error) {
+	val, has := keywords(ctx, obj)["@id"]
 	if !has {
-		return "", false
+		return anonymous(), nil
 	}
 
-	id, ok := raw.(string)
+	id, ok := val.(string)
 	if !ok {
-		return "", false
+		return "", fmt.Errorf("json-ld @id codec do not support %T (%v)", val, val)
 	}
 
-	return curie.IRI(id), true
+	return dec.iri(ctx, id, false), nil
 }
 
-func decodeObjectProperties(bag *Bag, s curie.IRI, obj map[string]any) error {
-	for key, val := range obj {
-		if key == "@id" {
+func (dec *decoder) properties(ctx *context, g, s curie.IRI, obj object) error {
+	kw := keywords(ctx, obj)
+	_, hasID := kw["@id"]
+	_, hasGraph := kw["@graph"]
+	_, hasContext := kw["@context"]
+
+	// the object is container of default graph if it has only @graph
+	graph := s
+	if !hasID && hasGraph && (len(obj) == 1 || (len(obj) == 2 && hasContext)) {
+		graph = g
+	}
+
+	for _, m := range obj {
+		key := ctx.expand(m.key, true)
+		switch key {
+		case "@context", "@id":
 			continue
-		}
-		p := curie.IRI(key)
-
-		switch o := val.(type) {
-		case float64:
-			*bag = append(*bag, hexer.From(s, p, o))
-		case string:
-			*bag = append(*bag, hexer.From(s, p, o))
-		case bool:
-			*bag = append(*bag, hexer.From(s, p, o))
-		case map[string]any:
-			if err := decodeNodeObject(bag, s, p, o); err != nil {
+		case "@type":
+			if err := dec.types(ctx, g, s, m.val); err != nil {
 				return err
 			}
-		case []any:
-			if err := decodeNodeArray(bag, s, p, o); err != nil {
-				return err
+		case "@graph":
+			switch v := m.val.(type) {
+			case []any:
+				if err := dec.nodes(ctx, graph, v); err != nil {
+					return err
+				}
+			case object:
+				if _, err := dec.node(ctx, graph, v); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("json-ld graph codec do not support %T (%v)", m.val, m.val)
 			}
 		default:
-			return fmt.Errorf("json-ld object codec do not support %T (%v)", val, val)
+			// other keywords (@index, @reverse, etc) are not supported
+			if strings.HasPrefix(key, "@") {
+				continue
+			}
+
+			t, has := ctx.terms[m.key]
+			if !has {
+				t = &term{}
+			}
+
+			vctx := ctx
+			if t.context != nil {
+				var err error
+				if vctx, err = dec.local(ctx, t.context, 0); err != nil {
+					return err
+				}
+			}
+
+			p := dec.iri(ctx, m.key, true)
+			if err := dec.values(vctx, g, s, p, t, m.val); err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
-func decodeNodeObject(bag *Bag, s, p curie.IRI, node map[string]any) error {
-	val, has := node["@value"]
-	if has {
-		return decodeValue(bag, s, p, val)
+func (dec *decoder) types(ctx *context, g, s curie.IRI, val any) error {
+	switch v := val.(type) {
+	case string:
+		dec.emit(g, s, rdfType, xsd.AnyURI(dec.iri(ctx, v, true)))
+	case []any:
+		for _, x := range v {
+			if err := dec.types(ctx, g, s, x); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("json-ld @type codec do not support %T (%v)", val, val)
+	}
+	return nil
+}
+
+func (dec *decoder) values(ctx *context, g, s, p curie.IRI, t *term, val any) error {
+	switch v := val.(type) {
+	case nil:
+		return nil
+	case []any:
+		if t.container == "@list" {
+			return dec.list(ctx, g, s, p, t, v)
+		}
+		for _, x := range v {
+			if err := dec.values(ctx, g, s, p, t, x); err != nil {
+				return err
+			}
+		}
+		return nil
+	case object:
+		return dec.object(ctx, g, s, p, t, v)
+	case string:
+		o, err := dec.string(ctx, t, v)
+		if err != nil {
+			return err
+		}
+		dec.emit(g, s, p, o)
+		return nil
+	case json.Number:
+		o, err := dec.number(ctx, t.typ, v)
+		if err != nil {
+			return err
+		}
+		dec.emit(g, s, p, o)
+		return nil
+	case bool:
+		dec.emit(g, s, p, xsd.Boolean(v))
+		return nil
+	default:
+		return fmt.Errorf("json-ld value codec do not support %T (%v)", val, val)
+	}
+}
+
+// value object, list, set, language map or node object
+func (dec *decoder) object(ctx *context, g, s, p curie.IRI, t *term, obj object) error {
+	if t.container == "@language" {
+		return dec.languages(g, s, p, obj)
 	}
 
-	iri, has := decodeObjectID(node)
-	if has {
-		*bag = append(*bag, hexer.From(s, p, iri))
+	kw := keywords(ctx, obj)
+
+	if _, has := kw["@value"]; has {
+		o, err := dec.literal(ctx, kw)
+		if err != nil {
+			return err
+		}
+		if o != nil {
+			dec.emit(g, s, p, o)
+		}
 		return nil
 	}
 
-	return fmt.Errorf("json-ld node object codec do not support %T (%v)", node, node)
+	if list, has := kw["@list"]; has {
+		seq, ok := list.([]any)
+		if !ok {
+			seq = []any{list}
+		}
+		return dec.list(ctx, g, s, p, t, seq)
+	}
+
+	if set, has := kw["@set"]; has {
+		return dec.values(ctx, g, s, p, t, set)
+	}
+
+	ctx, err := dec.scope(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	id, err := dec.subject(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	dec.emit(g, s, p, xsd.AnyURI(id))
+	return dec.properties(ctx, g, id, obj)
 }
 
-func decodeNodeArray(bag *Bag, s, p curie.IRI, array []any) error {
-	for _, val := range array {
-		switch o := val.(type) {
-		case float64:
-			*bag = append(*bag, hexer.From(s, p, o))
-		case string:
-			*bag = append(*bag, hexer.From(s, p, o))
-		case bool:
-			*bag = append(*bag, hexer.From(s, p, o))
-		case map[string]any:
-			decodeNodeObject(bag, s, p, o)
-		default:
-			return fmt.Errorf("json-ld node array codec do not support %T (%v)", val, val)
+func (dec *decoder) languages(g, s, p curie.IRI, obj object) error {
+	for _, m := range obj {
+		seq, ok := m.val.([]any)
+		if !ok {
+			seq = []any{m.val}
+		}
+
+		for _, x := range seq {
+			text, ok := x.(string)
+			if !ok {
+				return fmt.Errorf("json-ld language map codec do not support %T (%v)", x, x)
+			}
+
+			if m.key == "@none" {
+				dec.emit(g, s, p, xsd.String(text))
+			} else {
+				dec.emit(g, s, p, xsd.Lang(text, m.key))
+			}
 		}
 	}
+	return nil
+}
+
+func (dec *decoder) list(ctx *context, g, s, p curie.IRI, t *term, seq []any) error {
+	if len(seq) == 0 {
+		dec.emit(g, s, p, xsd.AnyURI(rdfNil))
+		return nil
+	}
+
+	item := *t
+	item.container = ""
+
+	node := anonymous()
+	dec.emit(g, s, p, xsd.AnyURI(node))
+
+	for i, x := range seq {
+		if err := dec.values(ctx, g, node, rdfFirst, &item, x); err != nil {
+			return err
+		}
+
+		next := rdfNil
+		if i < len(seq)-1 {
+			next = anonymous()
+		}
+		dec.emit(g, node, rdfRest, xsd.AnyURI(next))
+		node = next
+	}
 
 	return nil
 }
 
-func decodeValue(bag *Bag, s, p curie.IRI, val any) error {
-	switch o := val.(type) {
-	case float64:
-		*bag = append(*bag, hexer.From(s, p, o))
+func (dec *decoder) string(ctx *context, t *term, text string) (xsd.Value, error) {
+	switch t.typ {
+	case "@id":
+		return xsd.AnyURI(dec.iri(ctx, text, false)), nil
+	case "@vocab":
+		return xsd.AnyURI(dec.iri(ctx, text, true)), nil
+	case "", "@none":
+	default:
+		return dec.typed(ctx, t.typ, text)
+	}
+
+	switch {
+	case t.hasLang && t.lang != "":
+		return xsd.Lang(text, t.lang), nil
+	case !t.hasLang && ctx.lang != "":
+		return xsd.Lang(text, ctx.lang), nil
+	default:
+		return xsd.String(text), nil
+	}
+}
+
+// numbers are either integers or doubles unless type is coerced
+func (dec *decoder) number(ctx *context, typ string, n json.Number) (xsd.Value, error) {
+	if typ != "" && !strings.HasPrefix(typ, "@") {
+		return dec.typed(ctx, typ, n.String())
+	}
+
+	if v, err := n.Int64(); err == nil {
+		return xsd.Integer(v), nil
+	}
+
+	v, err := n.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("json-ld number codec do not support %v", n)
+	}
+
+	return xsd.Double(v), nil
+}
+
+// XML Schema data types are parsed regardless of prefixes
+func (dec *decoder) typed(ctx *context, dt string, lexical string) (xsd.Value, error) {
+	if iri := ctx.expand(dt, true); strings.HasPrefix(iri, xmlSchema) {
+		return xsd.Parse(curie.IRI(iri), lexical)
+	}
+
+	return xsd.Parse(dec.iri(ctx, dt, true), lexical)
+}
+
+// value object {"@value": ..., "@type": ..., "@language": ...}
+func (dec *decoder) literal(ctx *context, kw map[string]any) (xsd.Value, error) {
+	typ, _ := kw["@type"].(string)
+	lang, hasLang := kw["@language"].(string)
+
+	switch v := kw["@value"].(type) {
+	case nil:
+		return nil, nil
 	case string:
-		*bag = append(*bag, hexer.From(s, p, o))
+		switch {
+		case typ != "":
+			return dec.typed(ctx, typ, v)
+		case hasLang:
+			return xsd.Lang(v, lang), nil
+		default:
+			return xsd.String(v), nil
+		}
+	case json.Number:
+		return dec.number(ctx, typ, v)
 	case bool:
-		*bag = append(*bag, hexer.From(s, p, o))
+		if typ != "" {
+			return dec.typed(ctx, typ, strconv.FormatBool(v))
+		}
+		return xsd.Boolean(v), nil
 	default:
-		return fmt.Errorf("json-ld value codec do not support %T (%v)", val, val)
+		return nil, fmt.Errorf("json-ld value codec do not support %T (%v)", v, v)
 	}
-
-	return nil
 }
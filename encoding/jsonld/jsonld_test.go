@@ -9,13 +9,20 @@
 package jsonld_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fogfish/curie"
 	"github.com/fogfish/guid/v2"
 	"github.com/fogfish/hexer"
 	"github.com/fogfish/hexer/encoding/jsonld"
+	"github.com/fogfish/hexer/xsd"
 	"github.com/fogfish/it/v2"
 )
 
@@ -53,35 +60,45 @@ func TestJsonLdUnmarshal(t *testing.T) {
 		)
 	})
 
-	// t.Run("PropertyInt", func(t *testing.T) {
-	// 	it.Then(t).Should(
-	// 		Codec(t, `{
-	// 			"prop": 10
-	// 		}`).Equal(
-	// 			hexer.From(luid, "prop", 10),
-	// 		),
-	// 	)
-	// })
-
-	// t.Run("PropertyFloat", func(t *testing.T) {
-	// 	it.Then(t).Should(
-	// 		Codec(t, `{
-	// 			"prop": 10.0
-	// 		}`).Equal(
-	// 			hexer.From(luid, "prop", 10.0),
-	// 		),
-	// 	)
-	// })
-
-	// t.Run("PropertyBool", func(t *testing.T) {
-	// 	it.Then(t).Should(
-	// 		Codec(t, `{
-	// 			"prop": true
-	// 		}`).Equal(
-	// 			hexer.From(luid, "prop", true),
-	// 		),
-	// 	)
-	// })
+	t.Run("PropertyInt", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"prop": 10
+			}`).Equal(
+				hexer.From(luid, "prop", 10),
+			),
+		)
+	})
+
+	t.Run("PropertyFloat", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"prop": 10.0
+			}`).Equal(
+				hexer.From(luid, "prop", 10.0),
+			),
+		)
+	})
+
+	t.Run("PropertyBool", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"prop": true
+			}`).Equal(
+				hexer.From(luid, "prop", true),
+			),
+		)
+	})
+
+	t.Run("PropertyLangString", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"prop": {"@value": "title", "@language": "en-GB"}
+			}`).Equal(
+				hexer.FromLang(luid, "prop", "title", "en-gb"),
+			),
+		)
+	})
 
 	t.Run("PropertyArray", func(t *testing.T) {
 		it.Then(t).Should(
@@ -95,17 +112,17 @@ func TestJsonLdUnmarshal(t *testing.T) {
 		)
 	})
 
-	// t.Run("PropertyArrayHeterogenous", func(t *testing.T) {
-	// 	it.Then(t).Should(
-	// 		Codec(t, `{
-	// 			"prop": [1, "b", true]
-	// 		}`).Equal(
-	// 			hexer.From(luid, "prop", 1),
-	// 			hexer.From(luid, "prop", "b"),
-	// 			hexer.From(luid, "prop", true),
-	// 		),
-	// 	)
-	// })
+	t.Run("PropertyArrayHeterogenous", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"prop": [1, "b", true]
+			}`).Equal(
+				hexer.From(luid, "prop", 1),
+				hexer.From(luid, "prop", "b"),
+				hexer.From(luid, "prop", true),
+			),
+		)
+	})
 
 	t.Run("ArrayOfObjects", func(t *testing.T) {
 		it.Then(t).Should(
@@ -146,3 +163,439 @@ func TestJsonLdUnmarshal(t *testing.T) {
 		)
 	})
 }
+
+func TestJsonLdContext(t *testing.T) {
+	guid.Clock = guid.NewClockMock()
+	luid := curie.IRI("_:5...............")
+
+	contexts := map[string]string{
+		"https://schema.org": `{
+			"@context": {
+				"@vocab": "http://schema.org/",
+				"schema": "http://schema.org/",
+				"xsd": "http://www.w3.org/2001/XMLSchema#",
+				"id": "@id",
+				"type": "@type",
+				"knows": {"@id": "schema:knows", "@type": "@id"},
+				"birthDate": {"@id": "schema:birthDate", "@type": "xsd:date"}
+			}
+		}`,
+	}
+
+	loader := func(iri string) ([]byte, error) {
+		doc, has := contexts[iri]
+		if !has {
+			return nil, fmt.Errorf("not found")
+		}
+		return []byte(doc), nil
+	}
+
+	Codec := func(t *testing.T, input string, opts ...jsonld.Option) it.SeqOf[hexer.SPOCK] {
+		t.Helper()
+		bag := jsonld.Bag{}
+		dec := jsonld.NewDecoder(strings.NewReader(input), append([]jsonld.Option{jsonld.WithLoader(loader)}, opts...)...)
+		err := dec.Decode(&bag)
+		it.Then(t).Should(it.Nil(err))
+
+		return it.Seq(bag)
+	}
+
+	t.Run("Terms", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": {
+					"ex": "http://example.com/",
+					"name": "ex:name",
+					"knows": {"@id": "ex:knows", "@type": "@id"}
+				},
+				"@id": "ex:a",
+				"name": "a",
+				"knows": "http://example.com/b",
+				"ex:age": 42,
+				"http://example.com/height": 1.5
+			}`).Equal(
+				hexer.From("ex:a", "ex:name", "a"),
+				hexer.From("ex:a", "ex:knows", curie.IRI("ex:b")),
+				hexer.From("ex:a", "ex:age", 42),
+				hexer.From("ex:a", "ex:height", 1.5),
+			),
+		)
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": {"@vocab": "http://example.com/"},
+				"@id": "http://example.com/a",
+				"@type": ["Person", "Agent"]
+			}`,
+				jsonld.WithNamespaces(curie.Namespaces{"ex": "http://example.com/"}),
+			).Equal(
+				hexer.From("ex:a", "rdf:type", curie.IRI("ex:Person")),
+				hexer.From("ex:a", "rdf:type", curie.IRI("ex:Agent")),
+			),
+		)
+	})
+
+	t.Run("Base", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": {
+					"@base": "http://example.com/a/",
+					"ref": {"@id": "http://example.com/ref", "@type": "@id"}
+				},
+				"@id": "b",
+				"ref": "../c"
+			}`).Equal(
+				hexer.From("http://example.com/a/b", "http://example.com/ref", curie.IRI("http://example.com/c")),
+			),
+		)
+	})
+
+	t.Run("TypedValues", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": {
+					"ex": "http://example.com/",
+					"xsd": "http://www.w3.org/2001/XMLSchema#",
+					"date": {"@id": "ex:date", "@type": "xsd:date"},
+					"count": {"@id": "ex:count", "@type": "xsd:integer"}
+				},
+				"@id": "ex:a",
+				"date": "2023-01-02",
+				"count": "42",
+				"ex:at": {"@value": "2023-01-02T03:04:05Z", "@type": "xsd:dateTime"},
+				"ex:size": {"@value": 10, "@type": "http://www.w3.org/2001/XMLSchema#decimal"}
+			}`).Equal(
+				hexer.From("ex:a", "ex:date", xsd.Date(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC))),
+				hexer.From("ex:a", "ex:count", 42),
+				hexer.From("ex:a", "ex:at", time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+				hexer.From("ex:a", "ex:size", xsd.Decimal(10)),
+			),
+		)
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": {
+					"@language": "en",
+					"ex": "http://example.com/",
+					"label": {"@id": "ex:label", "@container": "@language"},
+					"code": {"@id": "ex:code", "@language": null}
+				},
+				"@id": "ex:a",
+				"ex:name": "a",
+				"code": "x",
+				"label": {"fr": "texte", "de": ["text"]}
+			}`).Equal(
+				hexer.FromLang("ex:a", "ex:name", "a", "en"),
+				hexer.From("ex:a", "ex:code", "x"),
+				hexer.FromLang("ex:a", "ex:label", "texte", "fr"),
+				hexer.FromLang("ex:a", "ex:label", "text", "de"),
+			),
+		)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": {
+					"ex": "http://example.com/",
+					"seq": {"@id": "ex:seq", "@container": "@list"}
+				},
+				"@id": "ex:a",
+				"seq": [1],
+				"ex:nil": {"@list": []}
+			}`).Equal(
+				hexer.From("ex:a", "ex:seq", luid),
+				hexer.From(luid, "rdf:first", 1),
+				hexer.From(luid, "rdf:rest", curie.IRI("rdf:nil")),
+				hexer.From("ex:a", "ex:nil", curie.IRI("rdf:nil")),
+			),
+		)
+	})
+
+	t.Run("NestedNode", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": {"ex": "http://example.com/"},
+				"@id": "ex:a",
+				"ex:knows": {
+					"@id": "ex:b",
+					"@context": {"name": "ex:name"},
+					"name": "b"
+				}
+			}`).Equal(
+				hexer.From("ex:a", "ex:knows", curie.IRI("ex:b")),
+				hexer.From("ex:b", "ex:name", "b"),
+			),
+		)
+	})
+
+	t.Run("NamedGraph", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": {"ex": "http://example.com/"},
+				"@id": "ex:g",
+				"@graph": [
+					{"@id": "ex:a", "ex:name": "a"}
+				]
+			}`).Equal(
+				hexer.Quad("ex:g", "ex:a", "ex:name", "a"),
+			),
+		)
+	})
+
+	t.Run("RemoteContext", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `{
+				"@context": "https://schema.org",
+				"id": "http://example.com/a",
+				"type": "Person",
+				"name": "a",
+				"knows": "http://example.com/b",
+				"birthDate": "2000-01-02"
+			}`,
+				jsonld.WithNamespaces(curie.Namespaces{"ex": "http://example.com/"}),
+			).Equal(
+				hexer.From("ex:a", "rdf:type", curie.IRI("schema:Person")),
+				hexer.From("ex:a", "schema:name", "a"),
+				hexer.From("ex:a", "schema:knows", curie.IRI("ex:b")),
+				hexer.From("ex:a", "schema:birthDate", xsd.Date(time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC))),
+			),
+		)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, input := range []string{
+			`{"@context": "https://example.com/unknown", "a": "b"}`,
+			`{"@context": {"n": {"@id": "ex:n", "@type": "xsd:integer"}}, "n": "x"}`,
+			`{"@id": 1}`,
+			`{"a": "b"} {}`,
+		} {
+			bag := jsonld.Bag{}
+			err := jsonld.NewDecoder(strings.NewReader(input), jsonld.WithLoader(loader)).Decode(&bag)
+			it.Then(t).ShouldNot(it.Nil(err))
+		}
+	})
+}
+
+func TestJsonLdLoader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/context":
+			w.Write([]byte(`{"@context": {"name": "http://example.com/name"}}`))
+		case "/large":
+			w.Write([]byte(`{"@context": {"name": "`))
+			w.Write(bytes.Repeat([]byte("a"), 1<<20))
+			w.Write([]byte(`"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	Decode := func(input string, opts ...jsonld.Option) (jsonld.Bag, error) {
+		bag := jsonld.Bag{}
+		err := jsonld.NewDecoder(strings.NewReader(input), opts...).Decode(&bag)
+		return bag, err
+	}
+
+	doc := func(path string) string {
+		return `{"@context": "` + srv.URL + path + `", "@id": "ex:a", "name": "a"}`
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Error(Decode(doc("/context"))).Contain("not supported"),
+		)
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		bag, err := Decode(doc("/context"),
+			jsonld.WithLoader(jsonld.NewHTTPLoader(http.DefaultClient)),
+			jsonld.WithNamespaces(curie.Namespaces{"ex": "http://example.com/"}),
+		)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(bag).Equal(hexer.From("ex:a", "ex:name", "a")),
+		)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Error(Decode(doc("/none"), jsonld.WithLoader(jsonld.NewHTTPLoader(http.DefaultClient)))).Contain("http status 404"),
+		)
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Error(Decode(doc("/large"), jsonld.WithLoader(jsonld.NewHTTPLoader(http.DefaultClient)))).Contain("exceeds"),
+		)
+	})
+}
+
+func TestJsonLdEncoder(t *testing.T) {
+	ns := curie.Namespaces{"ex": "http://example.com/"}
+
+	Codec := func(t *testing.T, bag ...hexer.SPOCK) string {
+		t.Helper()
+		sb := strings.Builder{}
+		err := jsonld.NewEncoder(&sb, ns).Encode(hexer.NewStream(bag))
+		it.Then(t).Should(it.Nil(err))
+
+		return sb.String()
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		sb := strings.Builder{}
+		err := jsonld.NewEncoder(&sb, nil).Encode(hexer.NewStream(nil))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(sb.String(), "{\"@graph\":[]}\n"),
+		)
+	})
+
+	t.Run("Nodes", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(
+				Codec(t,
+					hexer.From("ex:a", "rdf:type", curie.IRI("ex:Person")),
+					hexer.From("ex:a", "ex:knows", curie.IRI("ex:b")),
+					hexer.From("ex:a", "ex:knows", curie.IRI("ex:c")),
+					hexer.From("ex:a", "ex:name", "a"),
+					hexer.FromLang("ex:a", "ex:label", "texte", "fr"),
+					hexer.From("ex:b", "ex:age", 42),
+					hexer.From("ex:b", "ex:score", 1.5),
+					hexer.From("ex:b", "ex:active", true),
+					hexer.Quad("ex:g", "ex:b", "ex:name", "b"),
+				),
+				`{"@context":{"ex":"http://example.com/"},"@graph":[
+  {"@id":"ex:a","@type":"ex:Person","ex:knows":[{"@id":"ex:b"},{"@id":"ex:c"}],"ex:label":{"@language":"fr","@value":"texte"},"ex:name":"a"},
+  {"@id":"ex:b","ex:active":true,"ex:age":42,"ex:score":{"@type":"xsd:double","@value":"1.5"}},
+  {"@graph":[{"@id":"ex:b","ex:name":"b"}],"@id":"ex:g"}
+]}
+`,
+			),
+		)
+	})
+}
+
+func TestJsonLdFrame(t *testing.T) {
+	ns := curie.Namespaces{"ex": "http://example.com/"}
+
+	bag := hexer.Bag{
+		hexer.From("ex:a", "rdf:type", curie.IRI("ex:Person")),
+		hexer.From("ex:a", "ex:name", "a"),
+		hexer.From("ex:a", "ex:knows", curie.IRI("ex:b")),
+		hexer.From("ex:a", "ex:address", curie.IRI("_:addr")),
+		hexer.From("ex:b", "rdf:type", curie.IRI("ex:Person")),
+		hexer.From("ex:b", "ex:name", "b"),
+		hexer.From("ex:b", "ex:knows", curie.IRI("ex:a")),
+		hexer.From("_:addr", "ex:city", "Helsinki"),
+	}
+
+	Codec := func(t *testing.T, frame jsonld.Frame) string {
+		t.Helper()
+		sb := strings.Builder{}
+		err := jsonld.NewFrameEncoder(&sb, ns, frame).Encode(hexer.NewStream(bag))
+		it.Then(t).Should(it.Nil(err))
+
+		return sb.String()
+	}
+
+	t.Run("Subject", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(
+				Codec(t, jsonld.Frame{Subject: "ex:a", Depth: 2}),
+				`{"@context":{"ex":"http://example.com/"},"@id":"ex:a","@type":"ex:Person","ex:address":{"ex:city":"Helsinki"},"ex:knows":{"@id":"ex:b","@type":"ex:Person","ex:knows":{"@id":"ex:a"},"ex:name":"b"},"ex:name":"a"}`+"\n",
+			),
+		)
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(
+				Codec(t, jsonld.Frame{Type: "ex:Person"}),
+				`{"@context":{"ex":"http://example.com/"},"@graph":[{"@id":"ex:a","@type":"ex:Person","ex:address":{"@id":"_:addr"},"ex:knows":{"@id":"ex:b"},"ex:name":"a"},{"@id":"ex:b","@type":"ex:Person","ex:knows":{"@id":"ex:a"},"ex:name":"b"},{"@id":"_:addr","ex:city":"Helsinki"}]}`+"\n",
+			),
+		)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		err := jsonld.NewFrameEncoder(&strings.Builder{}, ns, jsonld.Frame{Subject: "ex:c"}).Encode(hexer.NewStream(bag))
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		guid.Clock = guid.NewClockMock()
+		luid := curie.IRI("_:5...............")
+
+		seq := jsonld.Bag{}
+		err := json.Unmarshal([]byte(Codec(t, jsonld.Frame{Subject: "ex:a", Depth: 1})), &seq)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(
+				hexer.From("ex:a", "rdf:type", curie.IRI("ex:Person")),
+				hexer.From("ex:a", "ex:address", luid),
+				hexer.From(luid, "ex:city", "Helsinki"),
+				hexer.From("ex:a", "ex:knows", curie.IRI("ex:b")),
+				hexer.From("ex:b", "rdf:type", curie.IRI("ex:Person")),
+				hexer.From("ex:b", "ex:knows", curie.IRI("ex:a")),
+				hexer.From("ex:b", "ex:name", "b"),
+				hexer.From("ex:a", "ex:name", "a"),
+			),
+		)
+	})
+	Decode := func(t *testing.T, bag hexer.Bag, frame jsonld.Frame) hexer.Bag {
+		t.Helper()
+		sb := strings.Builder{}
+		err := jsonld.NewFrameEncoder(&sb, ns, frame).Encode(hexer.NewStream(bag))
+		it.Then(t).Should(it.Nil(err))
+
+		seq := jsonld.Bag{}
+		err = json.Unmarshal([]byte(sb.String()), &seq)
+		it.Then(t).Should(it.Nil(err))
+
+		return hexer.Bag(seq)
+	}
+
+	t.Run("Cycle", func(t *testing.T) {
+		cycle := hexer.Bag{
+			hexer.From("ex:a", "ex:knows", curie.IRI("ex:b")),
+			hexer.From("ex:b", "ex:knows", curie.IRI("ex:a")),
+			hexer.From("ex:c", "ex:name", "c"),
+		}
+
+		it.Then(t).Should(
+			it.Seq(Decode(t, cycle, jsonld.Frame{Depth: 2})).Equal(
+				hexer.From("ex:c", "ex:name", "c"),
+				hexer.From("ex:a", "ex:knows", curie.IRI("ex:b")),
+				hexer.From("ex:b", "ex:knows", curie.IRI("ex:a")),
+			),
+		)
+	})
+
+	t.Run("SharedBlankNode", func(t *testing.T) {
+		guid.Clock = guid.NewClockMock()
+		luid := curie.IRI("_:5...............")
+
+		shared := hexer.Bag{
+			hexer.From("ex:a", "ex:address", curie.IRI("_:addr")),
+			hexer.From("ex:b", "ex:address", curie.IRI("_:addr")),
+			hexer.From("_:addr", "ex:city", "Helsinki"),
+		}
+
+		it.Then(t).Should(
+			it.Seq(Decode(t, shared, jsonld.Frame{Depth: 1})).Equal(
+				hexer.From("ex:a", "ex:address", luid),
+				hexer.From(luid, "ex:city", "Helsinki"),
+				hexer.From("ex:b", "ex:address", luid),
+			),
+		)
+	})
+}
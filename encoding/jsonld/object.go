@@ -0,0 +1,90 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSON object which keeps order of members, statements are decoded in
+// the order of the document.
+type object []member
+
+type member struct {
+	key string
+	val any
+}
+
+func (obj object) get(key string) (any, bool) {
+	for _, m := range obj {
+		if m.key == key {
+			return m.val, true
+		}
+	}
+	return nil, false
+}
+
+// parse JSON document into object, []any, string, json.Number, bool or nil
+func parse(in io.Reader) (any, error) {
+	r := json.NewDecoder(in)
+	r.UseNumber()
+
+	val, err := parseValue(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.Token(); err != io.EOF {
+		return nil, fmt.Errorf("json-ld codec expects single document")
+	}
+
+	return val, nil
+}
+
+func parseValue(r *json.Decoder) (any, error) {
+	tkn, err := r.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tkn {
+	case json.Delim('{'):
+		obj := object{}
+		for r.More() {
+			key, err := r.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			val, err := parseValue(r)
+			if err != nil {
+				return nil, err
+			}
+
+			obj = append(obj, member{key: key.(string), val: val})
+		}
+		_, err := r.Token()
+		return obj, err
+	case json.Delim('['):
+		seq := []any{}
+		for r.More() {
+			val, err := parseValue(r)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, val)
+		}
+		_, err := r.Token()
+		return seq, err
+	default:
+		return tkn, nil
+	}
+}
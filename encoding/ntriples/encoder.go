@@ -0,0 +1,165 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package ntriples
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+// well-known namespaces of built-in data types
+var builtin = curie.Namespaces{
+	"xsd": xmlSchema,
+	"rdf": "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+}
+
+// Encoder of N-Triples (N-Quads) stream. Compact IRIs are expanded using
+// namespaces, statements of named graphs are written as N-Quads.
+type Encoder struct {
+	w      *bufio.Writer
+	ns     curie.Namespaces
+	blanks map[curie.IRI]string
+}
+
+// Create new encoder writing statements to the stream
+func NewEncoder(w io.Writer, ns curie.Namespaces) *Encoder {
+	if ns == nil {
+		ns = curie.Namespaces{}
+	}
+
+	return &Encoder{
+		w:      bufio.NewWriter(w),
+		ns:     ns,
+		blanks: map[curie.IRI]string{},
+	}
+}
+
+// Encode all statements of the stream
+func (enc *Encoder) Encode(stream hexer.Stream) error {
+	err := stream.FMap(func(spock hexer.SPOCK) error {
+		enc.resource(spock.S)
+		enc.w.WriteByte(' ')
+		enc.resource(spock.P)
+		enc.w.WriteByte(' ')
+		if err := enc.object(spock.O); err != nil {
+			return err
+		}
+		if spock.G != "" {
+			enc.w.WriteByte(' ')
+			enc.resource(spock.G)
+		}
+		_, err := enc.w.WriteString(" .\n")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return enc.w.Flush()
+}
+
+func (enc *Encoder) resource(iri curie.IRI) {
+	if strings.HasPrefix(string(iri), "_:") {
+		enc.w.WriteString(enc.blank(iri))
+		return
+	}
+
+	enc.w.WriteByte('<')
+	enc.w.WriteString(escapeIRI(expand(enc.ns, iri)))
+	enc.w.WriteByte('>')
+}
+
+// blank nodes are relabelled, identities (_:...) are not valid labels
+func (enc *Encoder) blank(iri curie.IRI) string {
+	label, has := enc.blanks[iri]
+	if !has {
+		label = fmt.Sprintf("_:b%d", len(enc.blanks))
+		enc.blanks[iri] = label
+	}
+	return label
+}
+
+func (enc *Encoder) object(o xsd.Value) error {
+	switch v := o.(type) {
+	case xsd.AnyURI:
+		enc.resource(curie.IRI(v))
+		return nil
+	case xsd.String:
+		enc.w.WriteString(quote(string(v)))
+		return nil
+	case xsd.LangString:
+		enc.w.WriteString(quote(v.Value))
+		enc.w.WriteByte('@')
+		enc.w.WriteString(v.Lang)
+		return nil
+	}
+
+	lexical, err := xsd.Lexical(o)
+	if err != nil {
+		return err
+	}
+
+	enc.w.WriteString(quote(lexical))
+	enc.w.WriteString("^^")
+	enc.resource(o.XSDType())
+	return nil
+}
+
+// expands compact IRI, the IRI is kept as is if prefix is not known
+func expand(ns curie.Namespaces, iri curie.IRI) string {
+	prefix, ref := curie.Seq(iri)
+	if uri, has := ns[prefix]; has {
+		return uri + ref
+	}
+	if uri, has := builtin[prefix]; has {
+		return uri + ref
+	}
+	return string(iri)
+}
+
+func quote(s string) string {
+	sb := strings.Builder{}
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func escapeIRI(s string) string {
+	sb := strings.Builder{}
+	for _, r := range s {
+		if r <= ' ' || strings.ContainsRune("<>\"{}|^`\\", r) {
+			sb.WriteString(fmt.Sprintf("\\u%04X", r))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
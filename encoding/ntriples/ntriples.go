@@ -0,0 +1,374 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+// Package ntriples implements stream decoder of N-Triples and N-Quads.
+// The document is decoded line by line, so that large files are processed
+// without loading them in memory.
+//
+// Absolute IRIs are compacted using the namespaces supplied by the caller
+// (see WithNamespaces), the IRI is kept as is if it has no known prefix.
+// Labelled blank nodes are replaced with unique identities (_:...), the
+// label is consistently mapped to same identity within the document.
+// The optional graph label of N-Quads defines the graph of the statement.
+package ntriples
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+const xmlSchema = "http://www.w3.org/2001/XMLSchema#"
+
+// Collection of knowledge statements
+type Bag hexer.Bag
+
+// UnmarshalText decodes N-Triples (N-Quads) document into the bag
+func (bag *Bag) UnmarshalText(b []byte) error {
+	dec := NewDecoder(strings.NewReader(string(b)))
+	return dec.FMap(func(spock hexer.SPOCK) error {
+		*bag = append(*bag, spock)
+		return nil
+	})
+}
+
+// Decoder of N-Triples (N-Quads) stream
+type Decoder struct {
+	r      *bufio.Reader
+	line   int
+	ns     curie.Namespaces
+	blanks map[string]curie.IRI
+	head   hexer.SPOCK
+	err    error
+	closed bool
+}
+
+// Option of the decoder
+type Option func(*Decoder)
+
+// WithNamespaces configures prefixes used to compact absolute IRIs
+func WithNamespaces(ns curie.Namespaces) Option {
+	return func(dec *Decoder) { dec.ns = ns }
+}
+
+// Create new decoder reading statements from the stream
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	dec := &Decoder{
+		r:      bufio.NewReader(r),
+		ns:     curie.Namespaces{},
+		blanks: map[string]curie.IRI{},
+	}
+
+	for _, opt := range opts {
+		opt(dec)
+	}
+
+	return dec
+}
+
+// Decode next statement from the stream, it returns io.EOF at the end
+func (dec *Decoder) Decode() (hexer.SPOCK, error) {
+	for {
+		text, err := dec.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return hexer.SPOCK{}, err
+		}
+		if err == io.EOF && text == "" {
+			return hexer.SPOCK{}, io.EOF
+		}
+
+		dec.line++
+		spock, has, perr := dec.parse(text)
+		if perr != nil {
+			return hexer.SPOCK{}, fmt.Errorf("ntriples: line %d: %w", dec.line, perr)
+		}
+		if has {
+			return spock, nil
+		}
+	}
+}
+
+// Head of the stream, the last decoded statement
+func (dec *Decoder) Head() hexer.SPOCK { return dec.head }
+
+// Next decodes statement, it returns false at the end of stream or on error
+func (dec *Decoder) Next() bool {
+	if dec.err != nil || dec.closed {
+		return false
+	}
+
+	spock, err := dec.Decode()
+	if err != nil {
+		if err != io.EOF {
+			dec.err = err
+		}
+		return false
+	}
+
+	dec.head = spock
+	return true
+}
+
+// FMap applies function over all statements of the stream
+func (dec *Decoder) FMap(f func(hexer.SPOCK) error) error {
+	for dec.Next() {
+		if err := f(dec.Head()); err != nil {
+			return err
+		}
+	}
+	return dec.err
+}
+
+// Err returns the error, if any, that was encountered during decoding
+func (dec *Decoder) Err() error { return dec.err }
+
+// Close terminates the stream, the reader is owned by caller and is not closed
+func (dec *Decoder) Close() error {
+	dec.closed = true
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+// line of N-Triples (N-Quads), it is either statement, comment or empty
+type scanner struct {
+	text string
+	pos  int
+}
+
+func (dec *Decoder) parse(text string) (hexer.SPOCK, bool, error) {
+	s := &scanner{text: text}
+
+	if s.skip(); s.eol() {
+		return hexer.SPOCK{}, false, nil
+	}
+
+	var spock hexer.SPOCK
+	var err error
+
+	if spock.S, err = dec.resource(s); err != nil {
+		return hexer.SPOCK{}, false, err
+	}
+
+	s.skip()
+	if s.peek() != '<' {
+		return hexer.SPOCK{}, false, s.errorf("predicate IRI")
+	}
+	if spock.P, err = dec.iri(s); err != nil {
+		return hexer.SPOCK{}, false, err
+	}
+
+	if spock.O, err = dec.object(s); err != nil {
+		return hexer.SPOCK{}, false, err
+	}
+
+	if s.skip(); s.peek() != '.' {
+		if spock.G, err = dec.resource(s); err != nil {
+			return hexer.SPOCK{}, false, err
+		}
+	}
+
+	if s.skip(); s.peek() != '.' {
+		return hexer.SPOCK{}, false, s.errorf("'.'")
+	}
+	s.pos++
+
+	if s.skip(); !s.eol() {
+		return hexer.SPOCK{}, false, s.errorf("end of line")
+	}
+
+	return spock, true, nil
+}
+
+// IRI or blank node
+func (dec *Decoder) resource(s *scanner) (curie.IRI, error) {
+	s.skip()
+	switch {
+	case s.peek() == '<':
+		return dec.iri(s)
+	case strings.HasPrefix(s.text[s.pos:], "_:"):
+		return dec.blank(s), nil
+	default:
+		return "", s.errorf("IRI or blank node")
+	}
+}
+
+func (dec *Decoder) iri(s *scanner) (curie.IRI, error) {
+	uri, err := s.uri()
+	if err != nil {
+		return "", err
+	}
+
+	return curie.FromURI(dec.ns, uri), nil
+}
+
+func (s *scanner) uri() (string, error) {
+	at := s.pos
+	end := strings.IndexByte(s.text[at:], '>')
+	if end == -1 {
+		return "", s.errorf("'>'")
+	}
+
+	s.pos = at + end + 1
+	return unescape(s.text[at+1 : at+end])
+}
+
+func (dec *Decoder) blank(s *scanner) curie.IRI {
+	at := s.pos + 2
+	s.pos = at
+	for !s.eol() && !isSpace(s.text[s.pos]) && s.text[s.pos] != '<' {
+		s.pos++
+	}
+
+	// label might end with dot, which terminates statement
+	if s.pos > at && s.text[s.pos-1] == '.' && (s.eol() || isSpace(s.text[s.pos])) {
+		s.pos--
+	}
+
+	label := s.text[at:s.pos]
+	id, has := dec.blanks[label]
+	if !has {
+		id = curie.New("_:%s", guid.L(guid.Clock))
+		dec.blanks[label] = id
+	}
+
+	return id
+}
+
+func (dec *Decoder) object(s *scanner) (xsd.Value, error) {
+	s.skip()
+	if s.peek() != '"' {
+		iri, err := dec.resource(s)
+		if err != nil {
+			return nil, err
+		}
+		return xsd.AnyURI(iri), nil
+	}
+
+	at := s.pos
+	for s.pos++; s.pos < len(s.text) && s.text[s.pos] != '"'; s.pos++ {
+		if s.text[s.pos] == '\\' {
+			s.pos++
+		}
+	}
+	if s.pos >= len(s.text) {
+		return nil, s.errorf("'\"'")
+	}
+	s.pos++
+
+	lexical, err := unescape(s.text[at+1 : s.pos-1])
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case s.peek() == '@':
+		at := s.pos + 1
+		for s.pos++; !s.eol() && (s.text[s.pos] == '-' || isAlphaNum(s.text[s.pos])); s.pos++ {
+		}
+		return xsd.Lang(lexical, s.text[at:s.pos]), nil
+	case strings.HasPrefix(s.text[s.pos:], "^^"):
+		s.pos += 2
+		if s.peek() != '<' {
+			return nil, s.errorf("datatype IRI")
+		}
+		dt, err := s.uri()
+		if err != nil {
+			return nil, err
+		}
+		// XML Schema data types are parsed regardless of namespaces
+		if strings.HasPrefix(dt, xmlSchema) {
+			return xsd.Parse(curie.IRI(dt), lexical)
+		}
+		return xsd.Parse(curie.FromURI(dec.ns, dt), lexical)
+	default:
+		return xsd.String(lexical), nil
+	}
+}
+
+func (s *scanner) eol() bool { return s.pos >= len(s.text) || s.text[s.pos] == '#' }
+
+func (s *scanner) peek() byte {
+	if s.pos < len(s.text) {
+		return s.text[s.pos]
+	}
+	return 0
+}
+
+func (s *scanner) skip() {
+	for s.pos < len(s.text) && isSpace(s.text[s.pos]) {
+		s.pos++
+	}
+}
+
+func (s *scanner) errorf(expected string) error {
+	if s.pos >= len(s.text) || s.text[s.pos] == '\n' || s.text[s.pos] == '\r' {
+		return fmt.Errorf("expected %s, got end of line", expected)
+	}
+	return fmt.Errorf("expected %s at %d", expected, s.pos+1)
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+func isAlphaNum(c byte) bool {
+	return (c >= '0' && c <= '9') || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+// unescape string and IRI escape sequences, including \uXXXX and \UXXXXXXXX
+func unescape(s string) (string, error) {
+	if strings.IndexByte(s, '\\') == -1 {
+		return s, nil
+	}
+
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch c := s[i]; c {
+		case 't':
+			sb.WriteByte('\t')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u', 'U':
+			n := 4
+			if c == 'U' {
+				n = 8
+			}
+			if i+n >= len(s) {
+				return "", fmt.Errorf("invalid escape sequence \\%s", s[i:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence \\%s", s[i:i+1+n])
+			}
+			sb.WriteRune(rune(r))
+			i += n
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), nil
+}
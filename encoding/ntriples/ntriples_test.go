@@ -0,0 +1,211 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package ntriples_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/encoding/ntriples"
+	"github.com/fogfish/hexer/xsd"
+	"github.com/fogfish/it/v2"
+)
+
+func TestDecoder(t *testing.T) {
+	ns := curie.Namespaces{
+		"ex":  "http://example.com/",
+		"xsd": "http://www.w3.org/2001/XMLSchema#",
+	}
+
+	Codec := func(t *testing.T, input string) it.SeqOf[hexer.SPOCK] {
+		t.Helper()
+		bag := hexer.Bag{}
+		dec := ntriples.NewDecoder(strings.NewReader(input), ntriples.WithNamespaces(ns))
+		err := dec.FMap(bag.Join)
+		it.Then(t).Should(it.Nil(err))
+
+		return it.Seq(bag)
+	}
+
+	t.Run("IRI", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				# comment
+				<http://example.com/a> <http://example.com/p> <http://example.com/b> .
+				<http://other.com/a> <http://example.com/p> <http://example.com/b> . # comment
+			`).Equal(
+				hexer.From("ex:a", "ex:p", curie.IRI("ex:b")),
+				hexer.From("http://other.com/a", "ex:p", curie.IRI("ex:b")),
+			),
+		)
+	})
+
+	t.Run("Literals", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				<http://example.com/a> <http://example.com/p> "text" .
+				<http://example.com/a> <http://example.com/p> "a \"quoted\"\ttexté #1" .
+				<http://example.com/a> <http://example.com/p> "texte"@fr .
+				<http://example.com/a> <http://example.com/p> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+				<http://example.com/a> <http://example.com/p> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> .
+			`).Equal(
+				hexer.From("ex:a", "ex:p", "text"),
+				hexer.From("ex:a", "ex:p", "a \"quoted\"\ttexté #1"),
+				hexer.FromLang("ex:a", "ex:p", "texte", "fr"),
+				hexer.From("ex:a", "ex:p", 42),
+				hexer.From("ex:a", "ex:p", true),
+			),
+		)
+	})
+
+	t.Run("Quads", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				<http://example.com/a> <http://example.com/p> "text" <http://example.com/g> .
+				<http://example.com/a> <http://example.com/p> "text" .
+			`).Equal(
+				hexer.Quad("ex:g", "ex:a", "ex:p", "text"),
+				hexer.From("ex:a", "ex:p", "text"),
+			),
+		)
+	})
+
+	t.Run("BlankNode", func(t *testing.T) {
+		guid.Clock = guid.NewClockMock()
+		luid := curie.IRI("_:5...............")
+
+		it.Then(t).Should(
+			Codec(t, `
+				_:b1 <http://example.com/p> "text".
+				<http://example.com/a> <http://example.com/p> _:b1 .
+			`).Equal(
+				hexer.From(luid, "ex:p", "text"),
+				hexer.From("ex:a", "ex:p", luid),
+			),
+		)
+	})
+
+	t.Run("BlankNodeLabels", func(t *testing.T) {
+		guid.Clock = guid.NewClock()
+
+		bag := hexer.Bag{}
+		dec := ntriples.NewDecoder(strings.NewReader(`
+			_:b1 <http://example.com/p> _:b2 .
+			_:b1 <http://example.com/p> _:b1 .
+		`))
+		err := dec.FMap(bag.Join)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(bag), 2),
+			it.Equal(bag[0].S, bag[1].S),
+			it.Equal(xsd.Value(xsd.AnyURI(bag[0].S)), bag[1].O),
+		).ShouldNot(
+			it.Equal(xsd.Value(xsd.AnyURI(bag[0].S)), bag[0].O),
+		)
+	})
+
+	t.Run("Bag", func(t *testing.T) {
+		bag := ntriples.Bag{}
+		err := bag.UnmarshalText([]byte(`<a> <p> "text" .`))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(bag).Equal(hexer.From("a", "p", "text")),
+		)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, input := range []string{
+			`<a> <p> .`,
+			`<a> "p" "o" .`,
+			`<a> <p> "o"`,
+			`<a> <p> "o .`,
+			`<a> <p> "x"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+			`<a> <p> "o" . <b>`,
+		} {
+			dec := ntriples.NewDecoder(strings.NewReader("\n" + input))
+			err := dec.FMap(func(hexer.SPOCK) error { return nil })
+			it.Then(t).Should(
+				it.Fail(func() error { return err }).Contain("ntriples: line 2"),
+			)
+		}
+	})
+}
+
+func TestEncoder(t *testing.T) {
+	ns := curie.Namespaces{"ex": "http://example.com/"}
+
+	Codec := func(t *testing.T, bag ...hexer.SPOCK) string {
+		t.Helper()
+		sb := strings.Builder{}
+		err := ntriples.NewEncoder(&sb, ns).Encode(hexer.NewStream(bag))
+		it.Then(t).Should(it.Nil(err))
+
+		return sb.String()
+	}
+
+	t.Run("Triples", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(
+				Codec(t,
+					hexer.From("ex:a", "ex:p", curie.IRI("ex:b")),
+					hexer.From("ex:a", "ex:p", curie.IRI("u:b c")),
+					hexer.From("ex:a", "ex:p", "a \"quoted\"\ntext"),
+					hexer.FromLang("ex:a", "ex:p", "texte", "fr"),
+					hexer.From("ex:a", "ex:p", 42),
+					hexer.Quad("ex:g", "ex:a", "ex:p", true),
+				),
+				`<http://example.com/a> <http://example.com/p> <http://example.com/b> .
+<http://example.com/a> <http://example.com/p> <u:b\u0020c> .
+<http://example.com/a> <http://example.com/p> "a \"quoted\"\ntext" .
+<http://example.com/a> <http://example.com/p> "texte"@fr .
+<http://example.com/a> <http://example.com/p> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.com/a> <http://example.com/p> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> <http://example.com/g> .
+`,
+			),
+		)
+	})
+
+	t.Run("BlankNode", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(
+				Codec(t,
+					hexer.From("_:5...............", "ex:p", curie.IRI("_:6...............")),
+					hexer.From("_:6...............", "ex:p", "text"),
+				),
+				`_:b0 <http://example.com/p> _:b1 .
+_:b1 <http://example.com/p> "text" .
+`,
+			),
+		)
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		bag := hexer.Bag{
+			hexer.From("ex:a", "ex:p", curie.IRI("ex:b")),
+			hexer.From("ex:a", "ex:p", "a \"quoted\"\ntext"),
+			hexer.FromLang("ex:a", "ex:p", "texte", "fr"),
+			hexer.From("ex:a", "ex:p", 1.5),
+			hexer.Quad("ex:g", "ex:a", "ex:p", true),
+		}
+
+		seq := hexer.Bag{}
+		dec := ntriples.NewDecoder(strings.NewReader(Codec(t, bag...)), ntriples.WithNamespaces(ns))
+		err := dec.FMap(seq.Join)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(bag...),
+		)
+	})
+}
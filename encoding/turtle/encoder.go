@@ -0,0 +1,226 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package turtle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+// well-known namespaces of built-in data types
+var builtin = curie.Namespaces{
+	"xsd": xmlSchema,
+	"rdf": "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+}
+
+// Encoder of Turtle stream. Statements of same subject are grouped using
+// predicate (;) and object (,) lists if they are adjacent in the stream,
+// e.g. stream of SPO index. IRIs are written as prefixed names if namespace
+// of prefix is known, the namespaces are declared using @prefix.
+// The graph of statement is ignored, use N-Quads to preserve it.
+type Encoder struct {
+	w      *bufio.Writer
+	ns     curie.Namespaces
+	blanks map[curie.IRI]string
+}
+
+// Create new encoder writing statements to the stream
+func NewEncoder(w io.Writer, ns curie.Namespaces) *Encoder {
+	if ns == nil {
+		ns = curie.Namespaces{}
+	}
+
+	return &Encoder{
+		w:      bufio.NewWriter(w),
+		ns:     ns,
+		blanks: map[curie.IRI]string{},
+	}
+}
+
+// Encode all statements of the stream
+func (enc *Encoder) Encode(stream hexer.Stream) error {
+	prefixes := make([]string, 0, len(enc.ns))
+	for prefix := range enc.ns {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	for _, prefix := range prefixes {
+		fmt.Fprintf(enc.w, "@prefix %s: <%s> .\n", prefix, escapeIRI(enc.ns[prefix]))
+	}
+	if len(prefixes) > 0 {
+		enc.w.WriteByte('\n')
+	}
+
+	var s, p curie.IRI
+	err := stream.FMap(func(spock hexer.SPOCK) error {
+		switch {
+		case s == "":
+			enc.w.WriteString(enc.resource(spock.S))
+			enc.w.WriteByte(' ')
+			enc.w.WriteString(enc.predicate(spock.P))
+		case s != spock.S:
+			enc.w.WriteString(" .\n\n")
+			enc.w.WriteString(enc.resource(spock.S))
+			enc.w.WriteByte(' ')
+			enc.w.WriteString(enc.predicate(spock.P))
+		case p != spock.P:
+			enc.w.WriteString(" ;\n    ")
+			enc.w.WriteString(enc.predicate(spock.P))
+		default:
+			enc.w.WriteString(",")
+		}
+		s, p = spock.S, spock.P
+
+		o, err := enc.object(spock.O)
+		if err != nil {
+			return err
+		}
+
+		enc.w.WriteByte(' ')
+		_, err = enc.w.WriteString(o)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if s != "" {
+		enc.w.WriteString(" .\n")
+	}
+
+	return enc.w.Flush()
+}
+
+func (enc *Encoder) predicate(iri curie.IRI) string {
+	if iri == "rdf:type" {
+		return "a"
+	}
+	return enc.resource(iri)
+}
+
+func (enc *Encoder) resource(iri curie.IRI) string {
+	prefix, ref := curie.Seq(iri)
+
+	switch {
+	case prefix == "_":
+		return enc.blank(iri)
+	case prefix == "":
+		return "<" + escapeIRI(string(iri)) + ">"
+	}
+
+	uri, has := enc.ns[prefix]
+	if has && isLocal(ref) {
+		return string(iri)
+	}
+
+	if !has {
+		if uri, has = builtin[prefix]; !has {
+			return "<" + escapeIRI(string(iri)) + ">"
+		}
+	}
+
+	return "<" + escapeIRI(uri+ref) + ">"
+}
+
+// blank nodes are relabelled, identities (_:...) are not valid labels
+func (enc *Encoder) blank(iri curie.IRI) string {
+	label, has := enc.blanks[iri]
+	if !has {
+		label = fmt.Sprintf("_:b%d", len(enc.blanks))
+		enc.blanks[iri] = label
+	}
+	return label
+}
+
+func (enc *Encoder) object(o xsd.Value) (string, error) {
+	switch v := o.(type) {
+	case xsd.AnyURI:
+		return enc.resource(curie.IRI(v)), nil
+	case xsd.String:
+		return quote(string(v)), nil
+	case xsd.LangString:
+		return quote(v.Value) + "@" + v.Lang, nil
+	case xsd.Integer, xsd.Boolean:
+		return fmt.Sprintf("%v", v), nil
+	}
+
+	lexical, err := xsd.Lexical(o)
+	if err != nil {
+		return "", err
+	}
+
+	// decimal shorthand requires the decimal point
+	if _, ok := o.(xsd.Decimal); ok && strings.Contains(lexical, ".") {
+		return lexical, nil
+	}
+
+	return quote(lexical) + "^^" + enc.resource(o.XSDType()), nil
+}
+
+// local part of prefixed name, it might contain dots but not at the end
+func isLocal(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for i, r := range s {
+		switch {
+		case r == '_' || r == ':' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		case (r == '-' || r == '.') && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return s[len(s)-1] != '.'
+}
+
+func quote(s string) string {
+	sb := strings.Builder{}
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func escapeIRI(s string) string {
+	sb := strings.Builder{}
+	for _, r := range s {
+		if r <= ' ' || strings.ContainsRune("<>\"{}|^`\\", r) {
+			sb.WriteString(fmt.Sprintf("\\u%04X", r))
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,361 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package turtle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// kind of lexical tokens
+type kind int
+
+const (
+	tEOF     kind = iota
+	tIRI          // <http://example.com/>
+	tPName        // prefixed name, e.g. foaf:name
+	tBlank        // _:label
+	tString       // "literal", 'literal', """literal""" or '''literal'''
+	tInteger      // 1
+	tDecimal      // 1.5
+	tDouble       // 1e3
+	tLang         // @en, also @prefix and @base directives
+	tType         // ^^
+	tWord         // a, true, false, PREFIX and BASE
+	tPunct        // . ; , [ ] ( )
+)
+
+type token struct {
+	kind kind
+	text string
+	line int
+}
+
+func (t token) String() string {
+	if t.kind == tEOF {
+		return "end of document"
+	}
+	return fmt.Sprintf("%q at line %d", t.text, t.line)
+}
+
+// lexer reads tokens from the stream, one at a time
+type lexer struct {
+	r    *bufio.Reader
+	line int
+}
+
+func newLexer(r io.Reader) *lexer {
+	return &lexer{r: bufio.NewReader(r), line: 1}
+}
+
+// look ahead n-th byte of the stream, 0 at the end of stream
+func (lex *lexer) peek(n int) byte {
+	b, err := lex.r.Peek(n + 1)
+	if err != nil || len(b) <= n {
+		return 0
+	}
+	return b[n]
+}
+
+func (lex *lexer) read() (rune, error) {
+	r, _, err := lex.r.ReadRune()
+	if r == '\n' {
+		lex.line++
+	}
+	return r, err
+}
+
+func (lex *lexer) discard(n int) {
+	for i := 0; i < n; i++ {
+		lex.read()
+	}
+}
+
+func (lex *lexer) skip() {
+	for {
+		switch c := lex.peek(0); {
+		case c == '#':
+			for c := lex.peek(0); c != '\n' && c != 0; c = lex.peek(0) {
+				lex.read()
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			lex.read()
+		default:
+			return
+		}
+	}
+}
+
+func (lex *lexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: "+format, append([]any{lex.line}, args...)...)
+}
+
+func (lex *lexer) next() (token, error) {
+	lex.skip()
+
+	line := lex.line
+	c := lex.peek(0)
+	switch {
+	case c == 0:
+		if _, err := lex.r.Peek(1); err != nil && err != io.EOF {
+			return token{}, err
+		}
+		return token{kind: tEOF, line: line}, nil
+	case c == '<':
+		iri, err := lex.iri()
+		return token{kind: tIRI, text: iri, line: line}, err
+	case c == '"' || c == '\'':
+		s, err := lex.string()
+		return token{kind: tString, text: s, line: line}, err
+	case c == '@':
+		lex.read()
+		tag := lex.lang()
+		if tag == "" {
+			return token{}, lex.errorf("invalid language tag")
+		}
+		return token{kind: tLang, text: tag, line: line}, nil
+	case c == '^' && lex.peek(1) == '^':
+		lex.discard(2)
+		return token{kind: tType, text: "^^", line: line}, nil
+	case c == '_' && lex.peek(1) == ':':
+		lex.discard(2)
+		label := lex.local()
+		if label == "" {
+			return token{}, lex.errorf("invalid blank node label")
+		}
+		return token{kind: tBlank, text: label, line: line}, nil
+	case isDigit(c) || (c == '.' && isDigit(lex.peek(1))) || ((c == '+' || c == '-') && (isDigit(lex.peek(1)) || lex.peek(1) == '.')):
+		return lex.number()
+	case strings.IndexByte(".;,[]()", c) != -1:
+		lex.read()
+		return token{kind: tPunct, text: string(c), line: line}, nil
+	default:
+		return lex.word()
+	}
+}
+
+func (lex *lexer) iri() (string, error) {
+	lex.read()
+
+	sb := strings.Builder{}
+	for {
+		r, err := lex.read()
+		switch {
+		case err != nil:
+			return "", lex.errorf("unterminated IRI")
+		case r == '>':
+			return sb.String(), nil
+		case r == '\\':
+			if err := lex.escape(&sb); err != nil {
+				return "", err
+			}
+		case r <= ' ' || strings.ContainsRune("<\"{}|^`", r):
+			return "", lex.errorf("invalid IRI character %q", r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+func (lex *lexer) string() (string, error) {
+	quote, _ := lex.read()
+
+	long := lex.peek(0) == byte(quote) && lex.peek(1) == byte(quote)
+	if long {
+		lex.discard(2)
+	}
+
+	sb := strings.Builder{}
+	for {
+		r, err := lex.read()
+		switch {
+		case err != nil:
+			return "", lex.errorf("unterminated string")
+		case r == quote && !long:
+			return sb.String(), nil
+		case r == quote && lex.peek(0) == byte(quote) && lex.peek(1) == byte(quote):
+			lex.discard(2)
+			// long string might end with quotes, e.g. """a""""
+			for lex.peek(0) == byte(quote) {
+				sb.WriteRune(quote)
+				lex.read()
+			}
+			return sb.String(), nil
+		case r == '\\':
+			if err := lex.escape(&sb); err != nil {
+				return "", err
+			}
+		case (r == '\n' || r == '\r') && !long:
+			return "", lex.errorf("unterminated string")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+func (lex *lexer) escape(sb *strings.Builder) error {
+	c, err := lex.read()
+	if err != nil {
+		return lex.errorf("invalid escape sequence")
+	}
+
+	switch c {
+	case 't':
+		sb.WriteByte('\t')
+	case 'b':
+		sb.WriteByte('\b')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		hex := make([]rune, n)
+		for i := range hex {
+			if hex[i], err = lex.read(); err != nil {
+				return lex.errorf("invalid escape sequence")
+			}
+		}
+		r, err := strconv.ParseUint(string(hex), 16, 32)
+		if err != nil {
+			return lex.errorf("invalid escape sequence \\%c%s", c, string(hex))
+		}
+		sb.WriteRune(rune(r))
+	default:
+		sb.WriteRune(c)
+	}
+
+	return nil
+}
+
+func (lex *lexer) number() (token, error) {
+	line := lex.line
+	sb := strings.Builder{}
+	kind := tInteger
+
+	if c := lex.peek(0); c == '+' || c == '-' {
+		sb.WriteByte(c)
+		lex.read()
+	}
+
+	digits := func() {
+		for isDigit(lex.peek(0)) {
+			sb.WriteByte(lex.peek(0))
+			lex.read()
+		}
+	}
+
+	digits()
+	// the dot is either decimal point or end of statement
+	if lex.peek(0) == '.' && isDigit(lex.peek(1)) {
+		kind = tDecimal
+		sb.WriteByte('.')
+		lex.read()
+		digits()
+	}
+	if c := lex.peek(0); c == 'e' || c == 'E' {
+		kind = tDouble
+		sb.WriteByte(c)
+		lex.read()
+		if c := lex.peek(0); c == '+' || c == '-' {
+			sb.WriteByte(c)
+			lex.read()
+		}
+		digits()
+	}
+
+	return token{kind: kind, text: sb.String(), line: line}, nil
+}
+
+// keywords, booleans, prefixed names and `a`
+func (lex *lexer) word() (token, error) {
+	line := lex.line
+	prefix := lex.name()
+
+	if lex.peek(0) == ':' {
+		lex.read()
+		return token{kind: tPName, text: prefix + ":" + lex.local(), line: line}, nil
+	}
+
+	if prefix == "" {
+		r, _ := lex.read()
+		return token{}, lex.errorf("unexpected %q", r)
+	}
+
+	return token{kind: tWord, text: prefix, line: line}, nil
+}
+
+// reads runes while they satisfy the predicate, the predicate gets
+// the rune and the following byte
+func (lex *lexer) scan(accept func(r rune, next byte) bool) string {
+	sb := strings.Builder{}
+	for {
+		b, _ := lex.r.Peek(utf8.UTFMax + 1)
+		if len(b) == 0 {
+			return sb.String()
+		}
+
+		r, n := utf8.DecodeRune(b)
+		next := byte(0)
+		if n < len(b) {
+			next = b[n]
+		}
+
+		if !accept(r, next) {
+			return sb.String()
+		}
+
+		sb.WriteRune(r)
+		lex.read()
+	}
+}
+
+func (lex *lexer) name() string {
+	first := true
+	return lex.scan(func(r rune, next byte) bool {
+		ok := r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || (!first && r == '-')
+		first = false
+		return ok
+	})
+}
+
+// local part of prefixed name or blank node label, it might contain dots
+// but not at the end
+func (lex *lexer) local() string {
+	return lex.scan(func(r rune, next byte) bool {
+		if r == '.' {
+			return next == '_' || next == '-' || next == ':' || next == '%' || next >= 0x80 || isAlphaNum(next)
+		}
+		return r == '_' || r == '-' || r == ':' || r == '%' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	})
+}
+
+func (lex *lexer) lang() string {
+	return lex.scan(func(r rune, next byte) bool {
+		return r == '-' || (r < 0x80 && isAlphaNum(byte(r)))
+	})
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+func isAlphaNum(c byte) bool {
+	return isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
@@ -0,0 +1,518 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+// Package turtle implements stream decoder of Turtle documents. The document
+// is decoded statement by statement, so that large files are processed
+// without loading them in memory.
+//
+// Prefixed names are kept compact (e.g. foaf:name), absolute IRIs are
+// compacted using @prefix declarations of the document and namespaces
+// supplied by the caller (see WithNamespaces). Relative IRIs are resolved
+// against @base. Blank nodes are replaced with unique identities (_:...),
+// the label is consistently mapped to same identity within the document.
+// Collections are decoded into rdf:first, rdf:rest lists.
+package turtle
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+const xmlSchema = "http://www.w3.org/2001/XMLSchema#"
+
+const (
+	rdfType  = curie.IRI("rdf:type")
+	rdfFirst = curie.IRI("rdf:first")
+	rdfRest  = curie.IRI("rdf:rest")
+	rdfNil   = curie.IRI("rdf:nil")
+)
+
+// Collection of knowledge statements
+type Bag hexer.Bag
+
+// UnmarshalText decodes Turtle document into the bag
+func (bag *Bag) UnmarshalText(b []byte) error {
+	dec := NewDecoder(strings.NewReader(string(b)))
+	return dec.FMap(func(spock hexer.SPOCK) error {
+		*bag = append(*bag, spock)
+		return nil
+	})
+}
+
+// Decoder of Turtle stream
+type Decoder struct {
+	lex    *lexer
+	tkn    *token
+	base   *url.URL
+	ns     curie.Namespaces
+	blanks map[string]curie.IRI
+	queue  []hexer.SPOCK
+	head   hexer.SPOCK
+	err    error
+	closed bool
+}
+
+// Option of the decoder
+type Option func(*Decoder)
+
+// WithNamespaces configures prefixes used to compact absolute IRIs
+func WithNamespaces(ns curie.Namespaces) Option {
+	return func(dec *Decoder) {
+		for prefix, uri := range ns {
+			dec.ns[prefix] = uri
+		}
+	}
+}
+
+// WithBase configures the base IRI used to resolve relative IRIs
+func WithBase(base string) Option {
+	return func(dec *Decoder) { dec.base, _ = url.Parse(base) }
+}
+
+// Create new decoder reading statements from the stream
+func NewDecoder(r io.Reader, opts ...Option) *Decoder {
+	dec := &Decoder{
+		lex:    newLexer(r),
+		ns:     curie.Namespaces{},
+		blanks: map[string]curie.IRI{},
+	}
+
+	for _, opt := range opts {
+		opt(dec)
+	}
+
+	return dec
+}
+
+// Decode next statement from the stream, it returns io.EOF at the end
+func (dec *Decoder) Decode() (hexer.SPOCK, error) {
+	for len(dec.queue) == 0 {
+		if err := dec.statement(); err != nil {
+			if err == io.EOF {
+				return hexer.SPOCK{}, err
+			}
+			return hexer.SPOCK{}, fmt.Errorf("turtle: %w", err)
+		}
+	}
+
+	spock := dec.queue[0]
+	dec.queue = dec.queue[1:]
+	return spock, nil
+}
+
+// Head of the stream, the last decoded statement
+func (dec *Decoder) Head() hexer.SPOCK { return dec.head }
+
+// Next decodes statement, it returns false at the end of stream or on error
+func (dec *Decoder) Next() bool {
+	if dec.err != nil || dec.closed {
+		return false
+	}
+
+	spock, err := dec.Decode()
+	if err != nil {
+		if err != io.EOF {
+			dec.err = err
+		}
+		return false
+	}
+
+	dec.head = spock
+	return true
+}
+
+// FMap applies function over all statements of the stream
+func (dec *Decoder) FMap(f func(hexer.SPOCK) error) error {
+	for dec.Next() {
+		if err := f(dec.Head()); err != nil {
+			return err
+		}
+	}
+	return dec.err
+}
+
+// Err returns the error, if any, that was encountered during decoding
+func (dec *Decoder) Err() error { return dec.err }
+
+// Close terminates the stream, the reader is owned by caller and is not closed
+func (dec *Decoder) Close() error {
+	dec.closed = true
+	return nil
+}
+
+//------------------------------------------------------------------------------
+
+func (dec *Decoder) peek() (token, error) {
+	if dec.tkn == nil {
+		tkn, err := dec.lex.next()
+		if err != nil {
+			return token{}, err
+		}
+		dec.tkn = &tkn
+	}
+
+	return *dec.tkn, nil
+}
+
+func (dec *Decoder) next() (token, error) {
+	tkn, err := dec.peek()
+	dec.tkn = nil
+	return tkn, err
+}
+
+func (dec *Decoder) is(text string) bool {
+	tkn, err := dec.peek()
+	return err == nil && tkn.kind == tPunct && tkn.text == text
+}
+
+func (dec *Decoder) expect(text string) error {
+	tkn, err := dec.next()
+	if err != nil {
+		return err
+	}
+
+	if tkn.kind != tPunct || tkn.text != text {
+		return fmt.Errorf("expected '%s', got %v", text, tkn)
+	}
+
+	return nil
+}
+
+func (dec *Decoder) emit(s, p curie.IRI, o xsd.Value) {
+	dec.queue = append(dec.queue, hexer.SPOCK{S: s, P: p, O: o})
+}
+
+// statement is either directive or triples
+func (dec *Decoder) statement() error {
+	tkn, err := dec.peek()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case tkn.kind == tEOF:
+		return io.EOF
+	case tkn.kind == tLang && (tkn.text == "prefix" || tkn.text == "base"):
+		dec.next()
+		if err := dec.directive(tkn.text); err != nil {
+			return err
+		}
+		return dec.expect(".")
+	case tkn.kind == tWord && (strings.EqualFold(tkn.text, "prefix") || strings.EqualFold(tkn.text, "base")):
+		dec.next()
+		return dec.directive(strings.ToLower(tkn.text))
+	case tkn.kind == tPunct && tkn.text == "[":
+		dec.next()
+		s, err := dec.blankNodePropertyList()
+		if err != nil {
+			return err
+		}
+		if !dec.is(".") {
+			if err := dec.predicateObjectList(s); err != nil {
+				return err
+			}
+		}
+		return dec.expect(".")
+	default:
+		s, err := dec.subject()
+		if err != nil {
+			return err
+		}
+		if err := dec.predicateObjectList(s); err != nil {
+			return err
+		}
+		return dec.expect(".")
+	}
+}
+
+func (dec *Decoder) directive(kind string) error {
+	if kind == "prefix" {
+		tkn, err := dec.next()
+		if err != nil {
+			return err
+		}
+		if tkn.kind != tPName || !strings.HasSuffix(tkn.text, ":") {
+			return fmt.Errorf("expected prefix, got %v", tkn)
+		}
+
+		uri, err := dec.uri()
+		if err != nil {
+			return err
+		}
+
+		dec.ns[strings.TrimSuffix(tkn.text, ":")] = uri
+		return nil
+	}
+
+	uri, err := dec.uri()
+	if err != nil {
+		return err
+	}
+
+	dec.base, err = url.Parse(uri)
+	return err
+}
+
+// absolute IRI
+func (dec *Decoder) uri() (string, error) {
+	tkn, err := dec.next()
+	if err != nil {
+		return "", err
+	}
+
+	if tkn.kind != tIRI {
+		return "", fmt.Errorf("expected IRI, got %v", tkn)
+	}
+
+	return dec.resolve(tkn.text)
+}
+
+func (dec *Decoder) resolve(iri string) (string, error) {
+	if dec.base == nil {
+		return iri, nil
+	}
+
+	ref, err := url.Parse(iri)
+	if err != nil {
+		return "", fmt.Errorf("invalid IRI <%s>: %w", iri, err)
+	}
+
+	return dec.base.ResolveReference(ref).String(), nil
+}
+
+// IRI, either <iri> or prefixed name
+func (dec *Decoder) iri(tkn token) (curie.IRI, error) {
+	switch tkn.kind {
+	case tIRI:
+		uri, err := dec.resolve(tkn.text)
+		if err != nil {
+			return "", err
+		}
+		return curie.FromURI(dec.ns, uri), nil
+	case tPName:
+		prefix := tkn.text[:strings.IndexByte(tkn.text, ':')]
+		if _, has := dec.ns[prefix]; !has {
+			return "", fmt.Errorf("undefined prefix %v", tkn)
+		}
+		return curie.IRI(tkn.text), nil
+	default:
+		return "", fmt.Errorf("expected IRI, got %v", tkn)
+	}
+}
+
+func (dec *Decoder) blank(label string) curie.IRI {
+	id, has := dec.blanks[label]
+	if !has {
+		id = curie.New("_:%s", guid.L(guid.Clock))
+		dec.blanks[label] = id
+	}
+	return id
+}
+
+func (dec *Decoder) anonymous() curie.IRI {
+	return curie.New("_:%s", guid.L(guid.Clock))
+}
+
+func (dec *Decoder) subject() (curie.IRI, error) {
+	tkn, err := dec.next()
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case tkn.kind == tBlank:
+		return dec.blank(tkn.text), nil
+	case tkn.kind == tPunct && tkn.text == "(":
+		return dec.collection()
+	default:
+		return dec.iri(tkn)
+	}
+}
+
+func (dec *Decoder) predicateObjectList(s curie.IRI) error {
+	for {
+		tkn, err := dec.next()
+		if err != nil {
+			return err
+		}
+
+		p := rdfType
+		if tkn.kind != tWord || tkn.text != "a" {
+			if p, err = dec.iri(tkn); err != nil {
+				return err
+			}
+		}
+
+		if err := dec.objectList(s, p); err != nil {
+			return err
+		}
+
+		if !dec.is(";") {
+			return nil
+		}
+
+		// the sequence of ; is allowed, including trailing one
+		for dec.is(";") {
+			dec.next()
+		}
+
+		if dec.is(".") || dec.is("]") {
+			return nil
+		}
+	}
+}
+
+func (dec *Decoder) objectList(s, p curie.IRI) error {
+	for {
+		o, err := dec.object()
+		if err != nil {
+			return err
+		}
+		dec.emit(s, p, o)
+
+		if !dec.is(",") {
+			return nil
+		}
+		dec.next()
+	}
+}
+
+func (dec *Decoder) object() (xsd.Value, error) {
+	tkn, err := dec.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tkn.kind {
+	case tBlank:
+		return xsd.AnyURI(dec.blank(tkn.text)), nil
+	case tString:
+		return dec.literal(tkn.text)
+	case tInteger:
+		return xsd.Parse(xsd.XSD_INTEGER, strings.TrimPrefix(tkn.text, "+"))
+	case tDecimal:
+		return xsd.Parse(xsd.XSD_DECIMAL, tkn.text)
+	case tDouble:
+		return xsd.Parse(xsd.XSD_DOUBLE, tkn.text)
+	case tWord:
+		switch tkn.text {
+		case "true":
+			return xsd.Boolean(true), nil
+		case "false":
+			return xsd.Boolean(false), nil
+		}
+	case tPunct:
+		switch tkn.text {
+		case "[":
+			id, err := dec.blankNodePropertyList()
+			return xsd.AnyURI(id), err
+		case "(":
+			id, err := dec.collection()
+			return xsd.AnyURI(id), err
+		}
+	}
+
+	iri, err := dec.iri(tkn)
+	if err != nil {
+		return nil, fmt.Errorf("expected object, got %v", tkn)
+	}
+
+	return xsd.AnyURI(iri), nil
+}
+
+func (dec *Decoder) literal(lexical string) (xsd.Value, error) {
+	tkn, err := dec.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tkn.kind {
+	case tLang:
+		dec.next()
+		return xsd.Lang(lexical, tkn.text), nil
+	case tType:
+		dec.next()
+		dt, err := dec.next()
+		if err != nil {
+			return nil, err
+		}
+		return dec.typed(dt, lexical)
+	default:
+		return xsd.String(lexical), nil
+	}
+}
+
+// XML Schema data types are parsed regardless of prefixes
+func (dec *Decoder) typed(dt token, lexical string) (xsd.Value, error) {
+	uri := dt.text
+	if dt.kind == tPName {
+		at := strings.IndexByte(dt.text, ':')
+		uri = dec.ns[dt.text[:at]] + dt.text[at+1:]
+	}
+
+	if strings.HasPrefix(uri, xmlSchema) {
+		return xsd.Parse(curie.IRI(uri), lexical)
+	}
+
+	iri, err := dec.iri(dt)
+	if err != nil {
+		return nil, err
+	}
+
+	return xsd.Parse(iri, lexical)
+}
+
+// [ predicateObjectList ], the opening bracket is consumed
+func (dec *Decoder) blankNodePropertyList() (curie.IRI, error) {
+	id := dec.anonymous()
+	if dec.is("]") {
+		dec.next()
+		return id, nil
+	}
+
+	if err := dec.predicateObjectList(id); err != nil {
+		return "", err
+	}
+
+	return id, dec.expect("]")
+}
+
+// ( object* ), the opening parenthesis is consumed
+func (dec *Decoder) collection() (curie.IRI, error) {
+	head, node := rdfNil, curie.IRI("")
+
+	for !dec.is(")") {
+		o, err := dec.object()
+		if err != nil {
+			return "", err
+		}
+
+		next := dec.anonymous()
+		if node == "" {
+			head = next
+		} else {
+			dec.emit(node, rdfRest, xsd.AnyURI(next))
+		}
+		dec.emit(next, rdfFirst, o)
+		node = next
+	}
+	dec.next()
+
+	if node != "" {
+		dec.emit(node, rdfRest, xsd.AnyURI(rdfNil))
+	}
+
+	return head, nil
+}
@@ -0,0 +1,280 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package turtle_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/encoding/turtle"
+	"github.com/fogfish/hexer/xsd"
+	"github.com/fogfish/it/v2"
+)
+
+func TestDecoder(t *testing.T) {
+	guid.Clock = guid.NewClockMock()
+	luid := curie.IRI("_:5...............")
+
+	Codec := func(t *testing.T, input string, opts ...turtle.Option) it.SeqOf[hexer.SPOCK] {
+		t.Helper()
+		bag := hexer.Bag{}
+		dec := turtle.NewDecoder(strings.NewReader(input), opts...)
+		err := dec.FMap(bag.Join)
+		it.Then(t).Should(it.Nil(err))
+
+		return it.Seq(bag)
+	}
+
+	t.Run("Prefixes", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				@prefix ex: <http://example.com/> .
+				PREFIX foaf: <http://xmlns.com/foaf/0.1/>
+
+				ex:a foaf:knows <http://example.com/b> .
+				<http://other.com/a> a foaf:Person . # comment
+			`).Equal(
+				hexer.From("ex:a", "foaf:knows", curie.IRI("ex:b")),
+				hexer.From("http://other.com/a", "rdf:type", curie.IRI("foaf:Person")),
+			),
+		)
+	})
+
+	t.Run("Namespaces", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				<http://example.com/a> ex:p <b> .
+			`,
+				turtle.WithNamespaces(curie.Namespaces{"ex": "http://example.com/"}),
+				turtle.WithBase("http://example.com/"),
+			).Equal(
+				hexer.From("ex:a", "ex:p", curie.IRI("ex:b")),
+			),
+		)
+	})
+
+	t.Run("Base", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				@base <http://example.com/a/> .
+				<b> <p> <../c> .
+			`).Equal(
+				hexer.From("http://example.com/a/b", "http://example.com/a/p", curie.IRI("http://example.com/c")),
+			),
+		)
+	})
+
+	t.Run("PredicateObjectList", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				@prefix ex: <http://example.com/> .
+				ex:a ex:p ex:b, ex:c ;
+					ex:q ex:d ;
+					.
+			`).Equal(
+				hexer.From("ex:a", "ex:p", curie.IRI("ex:b")),
+				hexer.From("ex:a", "ex:p", curie.IRI("ex:c")),
+				hexer.From("ex:a", "ex:q", curie.IRI("ex:d")),
+			),
+		)
+	})
+
+	t.Run("Literals", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				@prefix ex: <http://example.com/> .
+				@prefix xs: <http://www.w3.org/2001/XMLSchema#> .
+				ex:a ex:p "text", 'text', "a \"quoted\" # text",
+					"""long "text"
+line""",
+					"texte"@fr,
+					"42"^^xs:integer,
+					"42"^^<http://www.w3.org/2001/XMLSchema#integer>,
+					42, -1.5, 1e3, true, false.
+			`).Equal(
+				hexer.From("ex:a", "ex:p", "text"),
+				hexer.From("ex:a", "ex:p", "text"),
+				hexer.From("ex:a", "ex:p", "a \"quoted\" # text"),
+				hexer.From("ex:a", "ex:p", "long \"text\"\nline"),
+				hexer.FromLang("ex:a", "ex:p", "texte", "fr"),
+				hexer.From("ex:a", "ex:p", 42),
+				hexer.From("ex:a", "ex:p", 42),
+				hexer.From("ex:a", "ex:p", 42),
+				hexer.From("ex:a", "ex:p", xsd.Decimal(-1.5)),
+				hexer.From("ex:a", "ex:p", xsd.Double(1000)),
+				hexer.From("ex:a", "ex:p", true),
+				hexer.From("ex:a", "ex:p", false),
+			),
+		)
+	})
+
+	t.Run("BlankNode", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				@prefix ex: <http://example.com/> .
+				ex:a ex:p [ ex:q "text" ] .
+				[ ex:q "text" ] .
+				_:b1 ex:q "text" .
+			`).Equal(
+				hexer.From(luid, "ex:q", "text"),
+				hexer.From("ex:a", "ex:p", luid),
+				hexer.From(luid, "ex:q", "text"),
+				hexer.From(luid, "ex:q", "text"),
+			),
+		)
+	})
+
+	t.Run("Collection", func(t *testing.T) {
+		it.Then(t).Should(
+			Codec(t, `
+				@prefix ex: <http://example.com/> .
+				ex:a ex:p ( 1 ) .
+				ex:a ex:p () .
+			`).Equal(
+				hexer.From(luid, "rdf:first", 1),
+				hexer.From(luid, "rdf:rest", curie.IRI("rdf:nil")),
+				hexer.From("ex:a", "ex:p", luid),
+				hexer.From("ex:a", "ex:p", curie.IRI("rdf:nil")),
+			),
+		)
+	})
+
+	t.Run("BlankNodeLabels", func(t *testing.T) {
+		guid.Clock = guid.NewClock()
+		defer func() { guid.Clock = guid.NewClockMock() }()
+
+		bag := hexer.Bag{}
+		dec := turtle.NewDecoder(strings.NewReader(`
+			_:b1 <p> _:b2, _:b1, [] .
+		`))
+		err := dec.FMap(bag.Join)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(bag), 3),
+			it.Equal(xsd.Value(xsd.AnyURI(bag[0].S)), bag[1].O),
+		).ShouldNot(
+			it.Equal(bag[0].O, bag[1].O),
+			it.Equal(bag[0].O, bag[2].O),
+		)
+	})
+
+	t.Run("Bag", func(t *testing.T) {
+		bag := turtle.Bag{}
+		err := bag.UnmarshalText([]byte(`<a> <p> "text" .`))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(bag).Equal(hexer.From("a", "p", "text")),
+		)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, input := range []string{
+			`<a> <p> .`,
+			`<a> "p" "o" .`,
+			`<a> <p> "o"`,
+			`<a> <p> "o .`,
+			`ex:a <p> "o" .`,
+			`<a> <p> "x"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+			`<a> <p> [ <q> "o" .`,
+			`<a> <p> ( "o" .`,
+		} {
+			dec := turtle.NewDecoder(strings.NewReader(input))
+			err := dec.FMap(func(hexer.SPOCK) error { return nil })
+			it.Then(t).Should(
+				it.Fail(func() error { return err }).Contain("turtle:"),
+			)
+		}
+	})
+}
+
+func TestEncoder(t *testing.T) {
+	ns := curie.Namespaces{
+		"ex":   "http://example.com/",
+		"foaf": "http://xmlns.com/foaf/0.1/",
+	}
+
+	Codec := func(t *testing.T, bag ...hexer.SPOCK) string {
+		t.Helper()
+		sb := strings.Builder{}
+		err := turtle.NewEncoder(&sb, ns).Encode(hexer.NewStream(bag))
+		it.Then(t).Should(it.Nil(err))
+
+		return sb.String()
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		sb := strings.Builder{}
+		err := turtle.NewEncoder(&sb, nil).Encode(hexer.NewStream(nil))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(sb.String(), ""),
+		)
+	})
+
+	t.Run("Pretty", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(
+				Codec(t,
+					hexer.From("ex:a", "rdf:type", curie.IRI("foaf:Person")),
+					hexer.From("ex:a", "foaf:knows", curie.IRI("ex:b")),
+					hexer.From("ex:a", "foaf:knows", curie.IRI("u:c")),
+					hexer.From("ex:a", "foaf:name", "a \"quoted\"\ntext"),
+					hexer.FromLang("ex:a", "foaf:name", "texte", "fr"),
+					hexer.From("ex:b", "foaf:age", 42),
+					hexer.From("ex:b", "ex:p", xsd.Decimal(1.5)),
+					hexer.From("ex:b", "ex:p", xsd.Double(1.5)),
+					hexer.From("ex:b", "ex:p", true),
+					hexer.From("ex:b", "ex:p", curie.IRI("ex:c/d")),
+					hexer.From("_:5...............", "ex:p", "text"),
+				),
+				`@prefix ex: <http://example.com/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+ex:a a foaf:Person ;
+    foaf:knows ex:b, <u:c> ;
+    foaf:name "a \"quoted\"\ntext", "texte"@fr .
+
+ex:b foaf:age 42 ;
+    ex:p 1.5, "1.5"^^<http://www.w3.org/2001/XMLSchema#double>, true, <http://example.com/c/d> .
+
+_:b0 ex:p "text" .
+`,
+			),
+		)
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		bag := hexer.Bag{
+			hexer.From("ex:a", "rdf:type", curie.IRI("foaf:Person")),
+			hexer.From("ex:a", "foaf:knows", curie.IRI("ex:b")),
+			hexer.From("ex:a", "foaf:name", "a \"quoted\"\ntext"),
+			hexer.FromLang("ex:a", "foaf:name", "texte", "fr"),
+			hexer.From("ex:b", "foaf:age", 42),
+			hexer.From("ex:b", "ex:p", xsd.Decimal(1.5)),
+			hexer.From("ex:b", "ex:p", xsd.Decimal(2)),
+			hexer.From("ex:b", "ex:p", 1.5),
+			hexer.From("ex:b", "ex:p", false),
+		}
+
+		seq := hexer.Bag{}
+		dec := turtle.NewDecoder(strings.NewReader(Codec(t, bag...)))
+		err := dec.FMap(seq.Join)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(seq).Equal(bag...),
+		)
+	})
+}
@@ -17,6 +17,7 @@ const (
 	LT         // Less Than
 	GT         // Greater Than
 	IN         // InRange, Between
+	VAR        // Variable, shared by patterns of basic graph pattern
 )
 
 // Predicate on <s,p,o>
@@ -24,9 +25,14 @@ type Predicate[T any] struct {
 	Clause Clause
 	Value  T
 	Other  T
+	Var    string // variable constrained by predicate, if defined
 }
 
 func (pred Predicate[T]) String() string {
+	if pred.Var != "" && pred.Clause != VAR {
+		return "?" + pred.Var + " " + Predicate[T]{Clause: pred.Clause, Value: pred.Value, Other: pred.Other}.String()
+	}
+
 	switch pred.Clause {
 	case EQ:
 		return fmt.Sprintf("= %v", pred.Value)
@@ -38,6 +44,8 @@ func (pred Predicate[T]) String() string {
 		return fmt.Sprintf("> %v", pred.Value)
 	case IN:
 		return fmt.Sprintf("[%v, %v]", pred.Value, pred.Other)
+	case VAR:
+		return "?" + pred.Var
 	default:
 		return ""
 	}
@@ -61,6 +69,16 @@ func (iri) HasPrefix(value curie.IRI) *Predicate[curie.IRI] {
 	return &Predicate[curie.IRI]{Clause: PQ, Value: value}
 }
 
+// Makes variable at IRI position
+func (iri) Var(name string) *Predicate[curie.IRI] {
+	return &Predicate[curie.IRI]{Clause: VAR, Var: name}
+}
+
+// Makes variable at value position
+func Var(name string) *Predicate[xsd.Value] {
+	return &Predicate[xsd.Value]{Clause: VAR, Var: name}
+}
+
 // Makes `equal to` value predicate
 func Eq[T xsd.DataType](value T) *Predicate[xsd.Value] {
 	return &Predicate[xsd.Value]{Clause: EQ, Value: xsd.From(value)}
@@ -71,6 +89,11 @@ func HasPrefix[T xsd.DataType](value T) *Predicate[xsd.Value] {
 	return &Predicate[xsd.Value]{Clause: PQ, Value: xsd.From(value)}
 }
 
+// Makes `language` predicate, it matches language-tagged strings by tag
+func HasLang(tag string) *Predicate[xsd.Value] {
+	return &Predicate[xsd.Value]{Clause: PQ, Value: xsd.Lang("", tag)}
+}
+
 // Makes `less than` value predicate
 func Lt[T xsd.DataType](value T) *Predicate[xsd.Value] {
 	return &Predicate[xsd.Value]{Clause: LT, Value: xsd.From(value)}
@@ -85,3 +108,33 @@ func Gt[T xsd.DataType](value T) *Predicate[xsd.Value] {
 func In[T xsd.DataType](from, to T) *Predicate[xsd.Value] {
 	return &Predicate[xsd.Value]{Clause: IN, Value: xsd.From(from), Other: xsd.From(to)}
 }
+
+type value string
+
+// Value makes predicates for any xsd.Value, including user-defined data-types
+const Value = value("")
+
+// Makes `equal to` value predicate
+func (value) Eq(v xsd.Value) *Predicate[xsd.Value] {
+	return &Predicate[xsd.Value]{Clause: EQ, Value: v}
+}
+
+// Makes `prefix` value predicate
+func (value) HasPrefix(v xsd.Value) *Predicate[xsd.Value] {
+	return &Predicate[xsd.Value]{Clause: PQ, Value: v}
+}
+
+// Makes `less than` value predicate
+func (value) Lt(v xsd.Value) *Predicate[xsd.Value] {
+	return &Predicate[xsd.Value]{Clause: LT, Value: v}
+}
+
+// Makes `greater than` value predicate
+func (value) Gt(v xsd.Value) *Predicate[xsd.Value] {
+	return &Predicate[xsd.Value]{Clause: GT, Value: v}
+}
+
+// Makes `in range` predicate
+func (value) In(from, to xsd.Value) *Predicate[xsd.Value] {
+	return &Predicate[xsd.Value]{Clause: IN, Value: from, Other: to}
+}
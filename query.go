@@ -42,8 +42,11 @@ const (
 	HINT_MATCH
 )
 
+// Pattern of knowledge statements, the nil graph predicate
+// matches the default graph.
 type Pattern struct {
 	Strategy                     Strategy
+	G                            *Predicate[curie.IRI]
 	S                            *Predicate[curie.IRI]
 	P                            *Predicate[curie.IRI]
 	O                            *Predicate[xsd.Value]
@@ -117,9 +120,18 @@ func (q Pattern) String() string {
 }
 
 func (q Pattern) Dump() string {
+	if q.G != nil {
+		return fmt.Sprintf("⟪%s : s %s, p %s, o %s, g %s⟫", q.String(), q.S, q.P, q.O, q.G)
+	}
 	return fmt.Sprintf("⟪%s : s %s, p %s, o %s⟫", q.String(), q.S, q.P, q.O)
 }
 
+// InGraph restricts the pattern to the named graph
+func (q Pattern) InGraph(g *Predicate[curie.IRI]) Pattern {
+	q.G = g
+	return q
+}
+
 func Query(
 	s *Predicate[curie.IRI],
 	p *Predicate[curie.IRI],
@@ -138,6 +150,8 @@ func Query(
 
 func hintFor[T any](pred *Predicate[T]) Hint {
 	switch {
+	case pred != nil && pred.Clause == VAR:
+		return HINT_NONE
 	case pred != nil && pred.Clause != EQ && pred.Clause != PQ:
 		return HINT_FILTER
 	case pred != nil && pred.Clause == PQ:
@@ -1,12 +1,42 @@
 package dynamo
 
 import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fogfish/curie"
+	"github.com/fogfish/guid/v2"
 	"github.com/fogfish/hexer/xsd"
 )
 
+//
+// Graph codec
+//
+
+// the default graph is persisted as "a" for compatibility with existing tables
+const defaultGraph = curie.IRI("a")
+
+func encodeG(g curie.IRI) curie.IRI {
+	if g == "" {
+		return defaultGraph
+	}
+	return g
+}
+
+// decodes graph from hash key "xx|g"
+func decodeG(key curie.IRI) curie.IRI {
+	g := key[3:]
+	if g == defaultGraph {
+		return ""
+	}
+	return g
+}
+
 //
 // Pair codec
 //
@@ -24,8 +54,12 @@ func decodeII(val string) (curie.IRI, curie.IRI) {
 	return curie.IRI(seq[0]), curie.IRI(seq[1])
 }
 
-func encodeIV(a curie.IRI, b xsd.Value) string {
-	return string(a) + "|" + encodeValue(b)
+func encodeIV(a curie.IRI, b xsd.Value) (string, error) {
+	v, err := encodeValue(b)
+	if err != nil {
+		return "", err
+	}
+	return string(a) + "|" + v, nil
 }
 
 func decodeIV(val string) (curie.IRI, xsd.Value) {
@@ -33,27 +67,69 @@ func decodeIV(val string) (curie.IRI, xsd.Value) {
 	return curie.IRI(seq[0]), decodeValue(seq[1])
 }
 
-func encodeVI(a xsd.Value, b curie.IRI) string {
-	return encodeValue(a) + "|" + string(b)
+func encodeVI(a xsd.Value, b curie.IRI) (string, error) {
+	v, err := encodeValue(a)
+	if err != nil {
+		return "", err
+	}
+	return v + "|" + string(b), nil
 }
 
+// value might contain `|`, IRI is the last element of the pair
 func decodeVI(val string) (xsd.Value, curie.IRI) {
-	seq := strings.SplitN(val, "|", 2)
-	return decodeValue(seq[0]), curie.IRI(seq[1])
+	at := strings.LastIndex(val, "|")
+	return decodeValue(val[:at]), curie.IRI(val[at+1:])
 }
 
 //
-// Value codec - ᴸᴵᴳ
+// Value codec - ᴸᴵᴳᴺᴰᶠᴱᴮᵀᵈᴾᴴᵇᶜ
+//
+// Numbers are encoded as fixed width hex of order-preserving bit pattern,
+// so that lexicographical order of encoded values matches numerical order.
+// Instants of time are encoded as fixed width UTC timestamps for same reason.
+// Binary values are hex-encoded, it preserves byte-wise order and prefixes.
+// User-defined values are encoded with registered codec, prefixed by data-type.
 //
 
-func encodeValue(value xsd.Value) string {
+func encodeValue(value xsd.Value) (string, error) {
 	switch v := value.(type) {
 	case xsd.AnyURI:
-		return "ᴵ" + string(v)
+		return "ᴵ" + string(v), nil
 	case xsd.String:
-		return "ᴸ" + string(v)
+		return "ᴸ" + string(v), nil
+	case xsd.LangString:
+		return "ᴳ" + v.Lang + "@" + v.Value, nil
+	case xsd.Integer:
+		return "ᴺ" + encodeUint64(uint64(v)^(1<<63)), nil
+	case xsd.Decimal:
+		return "ᴰ" + encodeFloat64(float64(v)), nil
+	case xsd.Float:
+		return "ᶠ" + encodeFloat64(float64(v)), nil
+	case xsd.Double:
+		return "ᴱ" + encodeFloat64(float64(v)), nil
+	case xsd.Boolean:
+		if v {
+			return "ᴮ1", nil
+		}
+		return "ᴮ0", nil
+	case xsd.DateTime:
+		return "ᵀ" + time.Time(v).UTC().Format(layoutDateTime), nil
+	case xsd.Date:
+		return "ᵈ" + time.Time(v).Format(time.DateOnly), nil
+	case xsd.Duration:
+		return "ᴾ" + encodeUint64(uint64(v)^(1<<63)), nil
+	case xsd.HexBinary:
+		return "ᴴ" + hex.EncodeToString(v), nil
+	case xsd.Base64Binary:
+		return "ᵇ" + hex.EncodeToString(v), nil
+	case nil:
+		return "", xsd.Validate(value)
 	default:
-		panic("not supported")
+		val, err := xsd.Encode(value)
+		if err != nil {
+			return "", err
+		}
+		return "ᶜ" + string(value.XSDType()) + "^" + val, nil
 	}
 }
 
@@ -63,7 +139,151 @@ func decodeValue(value string) xsd.Value {
 		return xsd.AnyURI(curie.IRI(value[3:]))
 	case "ᴸ":
 		return xsd.String(value[3:])
+	case "ᴳ":
+		seq := strings.SplitN(value[3:], "@", 2)
+		if len(seq) != 2 {
+			return nil
+		}
+		return xsd.LangString{Value: seq[1], Lang: seq[0]}
+	case "ᴺ":
+		return xsd.Integer(decodeUint64(value[3:]) ^ (1 << 63))
+	case "ᴰ":
+		return xsd.Decimal(decodeFloat64(value[3:]))
+	case "ᶠ":
+		return xsd.Float(decodeFloat64(value[3:]))
+	case "ᴱ":
+		return xsd.Double(decodeFloat64(value[3:]))
+	case "ᴮ":
+		return xsd.Boolean(value[3:] == "1")
+	case "ᵀ":
+		t, _ := time.Parse(layoutDateTime, value[3:])
+		return xsd.DateTime(t)
+	case "ᵈ":
+		t, _ := time.Parse(time.DateOnly, value[3:])
+		return xsd.Date(t)
+	case "ᴾ":
+		return xsd.Duration(decodeUint64(value[3:]) ^ (1 << 63))
+	case "ᴴ":
+		b, _ := hex.DecodeString(value[3:])
+		return xsd.HexBinary(b)
+	case "ᵇ":
+		b, _ := hex.DecodeString(value[3:])
+		return xsd.Base64Binary(b)
+	case "ᶜ":
+		seq := strings.SplitN(value[3:], "^", 2)
+		if len(seq) != 2 {
+			return nil
+		}
+		val, err := xsd.Decode(curie.IRI(seq[0]), seq[1])
+		if err != nil {
+			return nil
+		}
+		return val
 	}
 
 	return nil
 }
+
+const layoutDateTime = "2006-01-02T15:04:05.000000000Z"
+
+func encodeUint64(v uint64) string {
+	return fmt.Sprintf("%016x", v)
+}
+
+func decodeUint64(val string) uint64 {
+	v, err := strconv.ParseUint(val, 16, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// negative floats have all bits flipped, positive only sign bit
+func encodeFloat64(v float64) string {
+	bits := math.Float64bits(v)
+	if bits&(1<<63) != 0 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 63
+	}
+	return encodeUint64(bits)
+}
+
+func decodeFloat64(val string) float64 {
+	bits := decodeUint64(val)
+	if bits&(1<<63) != 0 {
+		bits &^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+	return math.Float64frombits(bits)
+}
+
+//
+// K-order codec
+//
+// The k-order of values is persisted by the item as the set of "k|value",
+// k is fixed width hex of Hi, Lo and local flag, so that lexicographical
+// order of k matches the k-order. The set contains multiple k-orders of
+// the value if it is re-inserted, the earliest one is the k-order of value.
+//
+
+// set of k-orders, the type distinguishes the attribute from the set of values
+type kset []string
+
+const lenK = 33
+
+func encodeK(k guid.K, value string) kset {
+	local := 0
+	if k.Local {
+		local = 1
+	}
+	return kset{fmt.Sprintf("%016x%016x%d|%s", k.Hi, k.Lo, local, value)}
+}
+
+// decodes the earliest k-order of each value
+func decodeK(seq kset) map[string]guid.K {
+	earliest := make(map[string]string, len(seq))
+	for _, x := range seq {
+		if len(x) <= lenK || x[lenK] != '|' {
+			continue
+		}
+
+		if k, has := earliest[x[lenK+1:]]; !has || x[:lenK] < k {
+			earliest[x[lenK+1:]] = x[:lenK]
+		}
+	}
+
+	ks := make(map[string]guid.K, len(earliest))
+	for value, k := range earliest {
+		ks[value] = guid.K{
+			Hi:    decodeUint64(k[:16]),
+			Lo:    decodeUint64(k[16:32]),
+			Local: k[32] == '1',
+		}
+	}
+
+	return ks
+}
+
+// k-orders of the value except the earliest one
+func laterK(seq kset, value string) kset {
+	ks := selectK(seq, value)
+	if len(ks) < 2 {
+		return nil
+	}
+
+	sort.Strings(ks)
+	return ks[1:]
+}
+
+// all k-orders of the value
+func selectK(seq kset, value string) kset {
+	ks := kset{}
+	for _, x := range seq {
+		if len(x) > lenK && x[lenK+1:] == value {
+			ks = append(ks, x)
+		}
+	}
+	return ks
+}
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/fogfish/curie"
+	"github.com/fogfish/dynamo/v2/service/ddb"
+	"github.com/fogfish/guid/v2"
 	"github.com/fogfish/hexer"
 	"github.com/fogfish/hexer/service/dynamo"
 	"github.com/fogfish/it/v2"
@@ -94,6 +96,18 @@ func TestSocialGraph(t *testing.T) {
 		return it.Seq(bag)
 	}
 
+	//
+	// #1: (___) ⇒ ∅
+	//
+
+	t.Run("#1: (___) ⇒ ∅", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t, "(___) ⇒ ∅",
+				hexer.Query(nil, nil, nil),
+			).Contain().AllOf(datasetSocialGraph()...),
+		)
+	})
+
 	//
 	// #2: (s) ⇒ po
 	//
@@ -235,27 +249,27 @@ func TestSocialGraph(t *testing.T) {
 
 	t.Run("#6: (s)º ⇒ p", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(s)º ⇒ p",
+			Seq(t, "(s)º ⇒ p",
 				hexer.Query(hexer.IRI.Equal(D), nil, hexer.Gt("a")),
 			).Equal(
-			// hexer.From(D, "status", "d"),
+				hexer.From(D, "status", "d"),
 			),
 		)
 	})
 
 	t.Run("#6: (s)º ⇒ p", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(s)º ⇒ p",
+			Seq(t, "(s)º ⇒ p",
 				hexer.Query(hexer.IRI.Equal(D), nil, hexer.Lt("x")),
 			).Equal(
-			// hexer.From(D, "status", "d"),
+				hexer.From(D, "status", "d"),
 			),
 		)
 	})
 
 	t.Run("#6: (s)º ⇒ p", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(s)º ⇒ p",
+			Seq(t, "(s)º ⇒ p",
 				hexer.Query(hexer.IRI.Equal(D), nil, hexer.Gt("x")),
 			).Equal(),
 		)
@@ -263,7 +277,7 @@ func TestSocialGraph(t *testing.T) {
 
 	t.Run("#6: (s)º ⇒ p", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(s)º ⇒ p",
+			Seq(t, "(s)º ⇒ p",
 				hexer.Query(hexer.IRI.Equal(D), nil, hexer.Lt("a")),
 			).Equal(),
 		)
@@ -560,42 +574,42 @@ func TestSocialGraph(t *testing.T) {
 
 	t.Run("#13: (p)º ⇒ s", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(p)º ⇒ s",
+			Seq(t, "(p)º ⇒ s",
 				hexer.Query(nil, hexer.IRI.Equal("status"), hexer.Gt("a")),
 			).Equal(
-			// hexer.From(B, "status", "b"),
-			// hexer.From(D, "status", "d"),
-			// hexer.From(G, "status", "g"),
+				hexer.From(B, "status", "b"),
+				hexer.From(D, "status", "d"),
+				hexer.From(G, "status", "g"),
 			),
 		)
 	})
 
 	t.Run("#13: (p)º ⇒ s", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(p)º ⇒ s",
+			Seq(t, "(p)º ⇒ s",
 				hexer.Query(nil, hexer.IRI.Equal("status"), hexer.Lt("x")),
 			).Equal(
-			// hexer.From(B, "status", "b"),
-			// hexer.From(D, "status", "d"),
-			// hexer.From(G, "status", "g"),
+				hexer.From(B, "status", "b"),
+				hexer.From(D, "status", "d"),
+				hexer.From(G, "status", "g"),
 			),
 		)
 	})
 
 	t.Run("#13: (p)º ⇒ s", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(p)º ⇒ s",
+			Seq(t, "(p)º ⇒ s",
 				hexer.Query(nil, hexer.IRI.Equal("status"), hexer.In("d", "g")),
 			).Equal(
-			// hexer.From(D, "status", "d"),
-			// hexer.From(G, "status", "g"),
+				hexer.From(D, "status", "d"),
+				hexer.From(G, "status", "g"),
 			),
 		)
 	})
 
 	t.Run("#13: (p)º ⇒ s", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(p)º ⇒ s",
+			Seq(t, "(p)º ⇒ s",
 				hexer.Query(nil, hexer.IRI.Equal("status"), hexer.Gt("x")),
 			).Equal(),
 		)
@@ -603,7 +617,7 @@ func TestSocialGraph(t *testing.T) {
 
 	t.Run("#13: (p)º ⇒ s", func(t *testing.T) {
 		it.Then(t).Should(
-			NotSupported(t, "(p)º ⇒ s",
+			Seq(t, "(p)º ⇒ s",
 				hexer.Query(nil, hexer.IRI.Equal("none"), hexer.Gt("a")),
 			).Equal(),
 		)
@@ -1125,3 +1139,167 @@ func TestSocialGraph(t *testing.T) {
 	})
 
 }
+
+func TestCut(t *testing.T) {
+	X := curie.IRI("x:X")
+	Y := curie.IRI("x:Y")
+
+	rds := setup(hexer.Bag{
+		hexer.From(X, "follows", Y),
+		hexer.From(X, "status", "x"),
+		hexer.From(Y, "status", "y"),
+	})
+
+	Seq := func(t *testing.T, req hexer.Pattern) it.SeqOf[hexer.SPOCK] {
+		t.Helper()
+		bag := hexer.Bag{}
+		seq, err := dynamo.Match(context.Background(), rds, req)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(seq.FMap(bag.Join)),
+		)
+
+		return it.Seq(bag)
+	}
+
+	t.Run("Cut", func(t *testing.T) {
+		err := dynamo.Cut(context.Background(), rds, hexer.From(X, "follows", Y))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			Seq(t, hexer.Query(hexer.IRI.Equal(X), nil, nil)).Equal(
+				hexer.From(X, "status", "x"),
+			),
+			Seq(t, hexer.Query(nil, nil, hexer.Eq(Y))).Equal(),
+		)
+	})
+
+	t.Run("CutMatch", func(t *testing.T) {
+		bag, err := dynamo.CutMatch(context.Background(), rds,
+			hexer.Query(hexer.IRI.HasPrefix("x:"), hexer.IRI.Equal("status"), nil),
+		)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(bag), 0),
+			Seq(t, hexer.Query(hexer.IRI.Equal(X), nil, nil)).Equal(),
+			Seq(t, hexer.Query(hexer.IRI.Equal(Y), nil, nil)).Equal(),
+		)
+	})
+}
+
+func TestGraph(t *testing.T) {
+	G1 := curie.IRI("g:1")
+	X := curie.IRI("x:X")
+
+	rds := setup(hexer.Bag{
+		hexer.Quad(G1, X, "follows", A),
+		hexer.From(X, "follows", B),
+	})
+
+	Seq := func(t *testing.T, req hexer.Pattern) it.SeqOf[hexer.SPOCK] {
+		t.Helper()
+		bag := hexer.Bag{}
+		seq, err := dynamo.Match(context.Background(), rds, req)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(seq.FMap(bag.Join)),
+		)
+
+		return it.Seq(bag)
+	}
+
+	it.Then(t).Should(
+		Seq(t, hexer.Query(hexer.IRI.Equal(X), nil, nil)).Equal(
+			hexer.From(X, "follows", B),
+		),
+		Seq(t, hexer.Query(hexer.IRI.Equal(X), nil, nil).InGraph(hexer.IRI.Equal(G1))).Equal(
+			hexer.Quad(G1, X, "follows", A),
+		),
+	)
+}
+
+// raw item of ⟨s,p,o⟩ index
+type itemSPO struct {
+	G  curie.IRI `dynamodbav:"prefix"`
+	SP string    `dynamodbav:"suffix"`
+	K  []string  `dynamodbav:"k,stringset,omitempty"`
+}
+
+func (x itemSPO) HashKey() curie.IRI { return x.G }
+func (x itemSPO) SortKey() curie.IRI { return curie.IRI(x.SP) }
+
+func TestReinsert(t *testing.T) {
+	ctx := context.Background()
+	G1 := curie.IRI("g:1")
+	X := curie.IRI("x:X")
+	early := guid.L(guid.Clock)
+
+	rds := setup(hexer.Bag{
+		hexer.Quad(G1, X, "follows", A),
+	})
+
+	spo, err := ddb.New[itemSPO]("ddb:///thingdb-latest")
+	if err != nil {
+		panic(err)
+	}
+
+	Size := func(t *testing.T) int {
+		t.Helper()
+		val, err := spo.Get(ctx, itemSPO{G: "sp|" + G1, SP: "x:X|follows"})
+		it.Then(t).Should(it.Nil(err))
+
+		return len(val.K)
+	}
+
+	K := func(t *testing.T) guid.K {
+		t.Helper()
+		bag := hexer.Bag{}
+		seq, err := dynamo.Match(ctx, rds, hexer.Query(hexer.IRI.Equal(X), nil, nil).InGraph(hexer.IRI.Equal(G1)))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(seq.FMap(bag.Join)),
+			it.Equal(len(bag), 1),
+		)
+
+		return bag[0].K
+	}
+
+	t.Run("Stamped", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			err := dynamo.Put(ctx, rds, hexer.Quad(G1, X, "follows", A))
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(Size(t), 1),
+			)
+		}
+	})
+
+	t.Run("Earlier", func(t *testing.T) {
+		spock := hexer.Quad(G1, X, "follows", A)
+		spock.K = early
+
+		for i := 0; i < 3; i++ {
+			err := dynamo.Put(ctx, rds, spock)
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(Size(t), 1),
+				it.Equal(K(t), early),
+			)
+		}
+	})
+
+	t.Run("Later", func(t *testing.T) {
+		spock := hexer.Quad(G1, X, "follows", A)
+		spock.K = guid.L(guid.Clock)
+
+		err := dynamo.Put(ctx, rds, spock)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(Size(t), 1),
+			it.Equal(K(t), early),
+		)
+	})
+}
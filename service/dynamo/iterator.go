@@ -9,25 +9,6 @@ import (
 	"github.com/fogfish/hexer"
 )
 
-/*
-
-todo:
-[x] dynamo cursor
-[x] iter of dynamo seq (abstract spo, sop, xxx types so that it is implemented once)
-3. iter of spo (set)  (abstract spo, sop, xxx types so that it is implemented once)
-
-for iter.Next() {
-	if err := f(iter.Head()); err != nil {
-		return err
-	}
-}
-
-How to abstract "Entity"?
-s -> map[p]o ?
-
-
-*/
-
 type none string
 
 func (none) MatchOpt() {}
@@ -35,10 +16,15 @@ func (none) MatchOpt() {}
 type Seq[T dynamo.Thing] interface {
 	Head() T
 	Next() bool
+	Err() error
+	Close() error
 }
 
-func NewIterator[T dynamo.Thing](store *ddb.Storage[T], query T) Seq[T] {
+// NewIterator creates iterator over pages of the query, pages are fetched
+// within the context.
+func NewIterator[T dynamo.Thing](ctx context.Context, store *ddb.Storage[T], query T) Seq[T] {
 	return &Iterator[T]{
+		ctx:    ctx,
 		store:  store,
 		query:  query,
 		cursor: none(""),
@@ -46,10 +32,12 @@ func NewIterator[T dynamo.Thing](store *ddb.Storage[T], query T) Seq[T] {
 }
 
 type Iterator[T dynamo.Thing] struct {
+	ctx    context.Context
 	store  *ddb.Storage[T]
 	query  T
 	cursor dynamo.MatchOpt
 	seq    []T
+	err    error
 }
 
 func (iter *Iterator[T]) Head() T {
@@ -62,18 +50,19 @@ func (iter *Iterator[T]) Next() bool {
 		return true
 	}
 
-	if iter.cursor == nil {
+	if iter.cursor == nil || iter.err != nil {
 		return false
 	}
 
-	var err error
-	iter.seq, iter.cursor, err = iter.store.Match(context.TODO(),
+	seq, cursor, err := iter.store.Match(iter.ctx,
 		iter.query, iter.cursor, dynamo.Limit(2),
 	)
 	if err != nil {
+		iter.seq, iter.cursor, iter.err = nil, nil, err
 		return false
 	}
 
+	iter.seq, iter.cursor = seq, cursor
 	if len(iter.seq) == 0 {
 		return false
 	}
@@ -81,9 +70,19 @@ func (iter *Iterator[T]) Next() bool {
 	return true
 }
 
+// Err returns failure of the query, e.g. throttling or cancellation
+func (iter *Iterator[T]) Err() error { return iter.err }
+
+// Close releases the cursor, no more pages are fetched
+func (iter *Iterator[T]) Close() error {
+	iter.seq, iter.cursor = nil, nil
+	return nil
+}
+
 type Unfold[T dynamo.Thing] struct {
 	seq Seq[T]
 	bag []hexer.SPOCK
+	err error
 }
 
 func (unfold *Unfold[T]) Head() hexer.SPOCK {
@@ -96,18 +95,24 @@ func (unfold *Unfold[T]) Next() bool {
 		return true
 	}
 
-	if !unfold.seq.Next() {
-		return false
-	}
+	for {
+		if unfold.err != nil || !unfold.seq.Next() {
+			return false
+		}
 
-	switch vv := any(unfold.seq.Head()).(type) {
-	case interface{ ToSPOCK() []hexer.SPOCK }:
-		unfold.bag = vv.ToSPOCK()
-	default:
-		fmt.Printf("==> %T\n", vv)
-	}
+		switch vv := any(unfold.seq.Head()).(type) {
+		case interface{ ToSPOCK() []hexer.SPOCK }:
+			unfold.bag = vv.ToSPOCK()
+		default:
+			unfold.err = fmt.Errorf("dynamo codec do not support %T", vv)
+			return false
+		}
 
-	return true
+		// item with empty set is left if Cut has failed to remove it
+		if len(unfold.bag) != 0 {
+			return true
+		}
+	}
 }
 
 func (unfold *Unfold[T]) FMap(f func(hexer.SPOCK) error) error {
@@ -116,65 +121,17 @@ func (unfold *Unfold[T]) FMap(f func(hexer.SPOCK) error) error {
 			return err
 		}
 	}
-	return nil
+	return unfold.Err()
 }
 
-/*
-func IT(store *Store) *Iterator3 {
-	return &Iterator3{store: store, eos: false}
-}
-
-type Iterator3 struct {
-	store  *Store
-	head   spo
-	tail   []spo
-	cursor *spo
-	eos    bool
-}
-
-func (iter *Iterator3) Head() {
-	sp, o := iter.head.SP, iter.head.O[0]
-	iter.head.O = iter.head.O[1:]
-
-	fmt.Printf("==> %v %v\n", sp, o)
-}
-
-func (iter *Iterator3) Next() bool {
-	g := curie.IRI("a")
-
-	if len(iter.head.O) == 0 && (iter.tail == nil || len(iter.tail) == 0) {
-		if iter.eos {
-			return false
-		}
-
-		key := spo{G: "sp|" + g}
-		if iter.cursor != nil {
-			key, iter.cursor = *iter.cursor, nil
-		}
-
-		fmt.Println("=====>>>>")
-		seq, _, err := iter.store.spo.Match(context.Background(), key, dynamo.Limit(2))
-		if err != nil {
-			return false
-		}
-		iter.head = seq[0]
-		iter.tail = seq[1:]
-
-		if len(seq) == 2 {
-			iter.cursor = &seq[len(seq)-1]
-			iter.eos = false
-		} else {
-			iter.eos = true
-		}
-
-		return true
-	}
-
-	if len(iter.head.O) == 0 {
-		iter.head = iter.tail[0]
-		iter.tail = iter.tail[1:]
+func (unfold *Unfold[T]) Err() error {
+	if unfold.err != nil {
+		return unfold.err
 	}
+	return unfold.seq.Err()
+}
 
-	return true
+func (unfold *Unfold[T]) Close() error {
+	unfold.bag = nil
+	return unfold.seq.Close()
 }
-*/
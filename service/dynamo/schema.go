@@ -2,17 +2,34 @@ package dynamo
 
 import (
 	"context"
+	"errors"
 
 	"github.com/fogfish/curie"
 	"github.com/fogfish/dynamo/v2/service/ddb"
 	"github.com/fogfish/hexer"
 )
 
+// Writer of knowledge statement into the index table.
+// Put returns true if the value is new in the table, the failed write
+// rolls back only values it has created.
+// PutK replaces k-order of the existing value if the given one is earlier,
+// the value keeps single k-order so that re-insert does not grow the item.
+// Cut removes the item from the table once its set of values is empty.
+// The item is removed on condition that the set is still empty, so that
+// values put concurrently are not lost.
 type Writer interface {
-	Put(ctx context.Context, store *Store) error
+	Put(ctx context.Context, store *Store) (bool, error)
+	PutK(ctx context.Context, store *Store) error
 	Cut(ctx context.Context, store *Store) error
 }
 
+// the condition of write is not met: the item is not removed since a value
+// is put after the set became empty, or the value exists
+func isPreConditionFailed(err error) bool {
+	var e interface{ PreConditionFailed() bool }
+	return errors.As(err, &e) && e.PreConditionFailed()
+}
+
 //
 // ⟨ Subject, Predicate, Object ⟩
 //
@@ -21,44 +38,98 @@ type spo struct {
 	G  curie.IRI `dynamodbav:"prefix"`
 	SP string    `dynamodbav:"suffix"`
 	O  []string  `dynamodbav:"o,stringset"`
+	K  kset      `dynamodbav:"k,stringset,omitempty"`
 }
 
 func (spo spo) HashKey() curie.IRI     { return spo.G }
 func (spo spo) SortKey() curie.IRI     { return curie.IRI(spo.SP) }
 func (spo spo) ToSPOCK() []hexer.SPOCK { return decodeSPO(spo) }
 
-func (spo spo) Put(ctx context.Context, store *Store) error {
+func (spo spo) Put(ctx context.Context, store *Store) (bool, error) {
 	_, err := store.spo.UpdateWith(ctx,
-		ddb.Updater(spo, _spo.Union(spo.O)),
+		ddb.Updater(spo, _spo.Union(spo.O), _spoK.Union(spo.K)),
+		_spoC.NotContains(spo.O[0]),
 	)
-	return err
+	if isPreConditionFailed(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (spo spo) PutK(ctx context.Context, store *Store) error {
+	val, err := store.spo.UpdateWith(ctx,
+		ddb.Updater(spo, _spoK.Union(spo.K)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if ks := laterK(val.K, spo.O[0]); len(ks) != 0 {
+		_, err = store.spo.UpdateWith(ctx,
+			ddb.Updater(spo, _spoK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 func (spo spo) Cut(ctx context.Context, store *Store) error {
-	_, err := store.spo.UpdateWith(ctx,
-		ddb.Updater(spo, _spo.Minus(spo.O)),
+	val, err := store.spo.UpdateWith(ctx,
+		ddb.Updater(spo, _spo.Minus(spo.O), _spoK.Minus(spo.K)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if len(val.O) == 0 {
+		_, err = store.spo.Remove(ctx, spo, _spoC.NotExists())
+		if !isPreConditionFailed(err) {
+			return err
+		}
+	}
+
+	// k-order is unknown if the statement is not read from the table
+	if ks := selectK(val.K, spo.O[0]); len(ks) != 0 {
+		_, err = store.spo.UpdateWith(ctx,
+			ddb.Updater(spo, _spoK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 var (
-	_spo = ddb.UpdateFor[spo, []string]()
+	_spo  = ddb.UpdateFor[spo, []string]()
+	_spoK = ddb.UpdateFor[spo, kset]()
+	_spoC = ddb.ClauseFor[spo, []string]()
 )
 
-func encodeSPO(g curie.IRI, spock hexer.SPOCK) spo {
+func encodeSPO(g curie.IRI, spock hexer.SPOCK) (spo, error) {
+	o, err := encodeValue(spock.O)
+	if err != nil {
+		return spo{}, err
+	}
+
 	return spo{
 		G:  "sp|" + g,
 		SP: encodeII(spock.S, spock.P),
-		O:  []string{encodeValue(spock.O)},
-	}
+		O:  []string{o},
+		K:  encodeK(spock.K, o),
+	}, nil
 }
 
 func decodeSPO(spo spo) []hexer.SPOCK {
+	g := decodeG(spo.G)
 	seq := make([]hexer.SPOCK, len(spo.O))
 	s, p := decodeII(spo.SP)
+	ks := decodeK(spo.K)
 
 	for i, o := range spo.O {
-		seq[i].S, seq[i].P, seq[i].O = s, p, decodeValue(o)
+		seq[i].G, seq[i].S, seq[i].P, seq[i].O = g, s, p, decodeValue(o)
+		seq[i].K = ks[o]
 	}
 
 	return seq
@@ -72,44 +143,98 @@ type sop struct {
 	G  curie.IRI   `dynamodbav:"prefix"`
 	SO string      `dynamodbav:"suffix"`
 	P  []curie.IRI `dynamodbav:"p,stringset"`
+	K  kset        `dynamodbav:"k,stringset,omitempty"`
 }
 
 func (sop sop) HashKey() curie.IRI     { return sop.G }
 func (sop sop) SortKey() curie.IRI     { return curie.IRI(sop.SO) }
 func (sop sop) ToSPOCK() []hexer.SPOCK { return decodeSOP(sop) }
 
-func (sop sop) Put(ctx context.Context, store *Store) error {
+func (sop sop) Put(ctx context.Context, store *Store) (bool, error) {
 	_, err := store.sop.UpdateWith(ctx,
-		ddb.Updater(sop, _sop.Union(sop.P)),
+		ddb.Updater(sop, _sop.Union(sop.P), _sopK.Union(sop.K)),
+		_sopC.NotContains(sop.P[0]),
+	)
+	if isPreConditionFailed(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (sop sop) PutK(ctx context.Context, store *Store) error {
+	val, err := store.sop.UpdateWith(ctx,
+		ddb.Updater(sop, _sopK.Union(sop.K)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if ks := laterK(val.K, string(sop.P[0])); len(ks) != 0 {
+		_, err = store.sop.UpdateWith(ctx,
+			ddb.Updater(sop, _sopK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 func (sop sop) Cut(ctx context.Context, store *Store) error {
-	_, err := store.sop.UpdateWith(ctx,
-		ddb.Updater(sop, _sop.Minus(sop.P)),
+	val, err := store.sop.UpdateWith(ctx,
+		ddb.Updater(sop, _sop.Minus(sop.P), _sopK.Minus(sop.K)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if len(val.P) == 0 {
+		_, err = store.sop.Remove(ctx, sop, _sopC.NotExists())
+		if !isPreConditionFailed(err) {
+			return err
+		}
+	}
+
+	// k-order is unknown if the statement is not read from the table
+	if ks := selectK(val.K, string(sop.P[0])); len(ks) != 0 {
+		_, err = store.sop.UpdateWith(ctx,
+			ddb.Updater(sop, _sopK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 var (
-	_sop = ddb.UpdateFor[sop, []curie.IRI]()
+	_sop  = ddb.UpdateFor[sop, []curie.IRI]()
+	_sopK = ddb.UpdateFor[sop, kset]()
+	_sopC = ddb.ClauseFor[sop, []curie.IRI]()
 )
 
-func encodeSOP(g curie.IRI, spock hexer.SPOCK) sop {
+func encodeSOP(g curie.IRI, spock hexer.SPOCK) (sop, error) {
+	so, err := encodeIV(spock.S, spock.O)
+	if err != nil {
+		return sop{}, err
+	}
+
 	return sop{
 		G:  "so|" + g,
-		SO: encodeIV(spock.S, spock.O),
+		SO: so,
 		P:  []curie.IRI{spock.P},
-	}
+		K:  encodeK(spock.K, string(spock.P)),
+	}, nil
 }
 
 func decodeSOP(sop sop) []hexer.SPOCK {
+	g := decodeG(sop.G)
 	seq := make([]hexer.SPOCK, len(sop.P))
 	s, o := decodeIV(sop.SO)
+	ks := decodeK(sop.K)
 
 	for i, p := range sop.P {
-		seq[i].S, seq[i].P, seq[i].O = s, p, o
+		seq[i].G, seq[i].S, seq[i].P, seq[i].O = g, s, p, o
+		seq[i].K = ks[string(p)]
 	}
 
 	return seq
@@ -123,44 +248,98 @@ type pos struct {
 	G  curie.IRI   `dynamodbav:"prefix"`
 	PO string      `dynamodbav:"suffix"`
 	S  []curie.IRI `dynamodbav:"s,stringset"`
+	K  kset        `dynamodbav:"k,stringset,omitempty"`
 }
 
 func (pos pos) HashKey() curie.IRI     { return pos.G }
 func (pos pos) SortKey() curie.IRI     { return curie.IRI(pos.PO) }
 func (pos pos) ToSPOCK() []hexer.SPOCK { return decodePOS(pos) }
 
-func (pos pos) Put(ctx context.Context, store *Store) error {
+func (pos pos) Put(ctx context.Context, store *Store) (bool, error) {
 	_, err := store.pos.UpdateWith(ctx,
-		ddb.Updater(pos, _pos.Union(pos.S)),
+		ddb.Updater(pos, _pos.Union(pos.S), _posK.Union(pos.K)),
+		_posC.NotContains(pos.S[0]),
 	)
-	return err
+	if isPreConditionFailed(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (pos pos) PutK(ctx context.Context, store *Store) error {
+	val, err := store.pos.UpdateWith(ctx,
+		ddb.Updater(pos, _posK.Union(pos.K)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if ks := laterK(val.K, string(pos.S[0])); len(ks) != 0 {
+		_, err = store.pos.UpdateWith(ctx,
+			ddb.Updater(pos, _posK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 func (pos pos) Cut(ctx context.Context, store *Store) error {
-	_, err := store.pos.UpdateWith(ctx,
-		ddb.Updater(pos, _pos.Minus(pos.S)),
+	val, err := store.pos.UpdateWith(ctx,
+		ddb.Updater(pos, _pos.Minus(pos.S), _posK.Minus(pos.K)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if len(val.S) == 0 {
+		_, err = store.pos.Remove(ctx, pos, _posC.NotExists())
+		if !isPreConditionFailed(err) {
+			return err
+		}
+	}
+
+	// k-order is unknown if the statement is not read from the table
+	if ks := selectK(val.K, string(pos.S[0])); len(ks) != 0 {
+		_, err = store.pos.UpdateWith(ctx,
+			ddb.Updater(pos, _posK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 var (
-	_pos = ddb.UpdateFor[pos, []curie.IRI]()
+	_pos  = ddb.UpdateFor[pos, []curie.IRI]()
+	_posK = ddb.UpdateFor[pos, kset]()
+	_posC = ddb.ClauseFor[pos, []curie.IRI]()
 )
 
-func encodePOS(g curie.IRI, spock hexer.SPOCK) pos {
+func encodePOS(g curie.IRI, spock hexer.SPOCK) (pos, error) {
+	po, err := encodeIV(spock.P, spock.O)
+	if err != nil {
+		return pos{}, err
+	}
+
 	return pos{
 		G:  "po|" + g,
-		PO: encodeIV(spock.P, spock.O),
+		PO: po,
 		S:  []curie.IRI{spock.S},
-	}
+		K:  encodeK(spock.K, string(spock.S)),
+	}, nil
 }
 
 func decodePOS(pos pos) []hexer.SPOCK {
+	g := decodeG(pos.G)
 	seq := make([]hexer.SPOCK, len(pos.S))
 	p, o := decodeIV(pos.PO)
+	ks := decodeK(pos.K)
 
 	for i, s := range pos.S {
-		seq[i].S, seq[i].P, seq[i].O = s, p, o
+		seq[i].G, seq[i].S, seq[i].P, seq[i].O = g, s, p, o
+		seq[i].K = ks[string(s)]
 	}
 
 	return seq
@@ -174,44 +353,98 @@ type pso struct {
 	G  curie.IRI `dynamodbav:"prefix"`
 	PS string    `dynamodbav:"suffix"`
 	O  []string  `dynamodbav:"o,stringset"`
+	K  kset      `dynamodbav:"k,stringset,omitempty"`
 }
 
 func (pso pso) HashKey() curie.IRI     { return pso.G }
 func (pso pso) SortKey() curie.IRI     { return curie.IRI(pso.PS) }
 func (pso pso) ToSPOCK() []hexer.SPOCK { return decodePSO(pso) }
 
-func (pso pso) Put(ctx context.Context, store *Store) error {
+func (pso pso) Put(ctx context.Context, store *Store) (bool, error) {
 	_, err := store.pso.UpdateWith(ctx,
-		ddb.Updater(pso, _pso.Union(pso.O)),
+		ddb.Updater(pso, _pso.Union(pso.O), _psoK.Union(pso.K)),
+		_psoC.NotContains(pso.O[0]),
 	)
-	return err
+	if isPreConditionFailed(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (pso pso) PutK(ctx context.Context, store *Store) error {
+	val, err := store.pso.UpdateWith(ctx,
+		ddb.Updater(pso, _psoK.Union(pso.K)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if ks := laterK(val.K, pso.O[0]); len(ks) != 0 {
+		_, err = store.pso.UpdateWith(ctx,
+			ddb.Updater(pso, _psoK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 func (pso pso) Cut(ctx context.Context, store *Store) error {
-	_, err := store.pso.UpdateWith(ctx,
-		ddb.Updater(pso, _pso.Minus(pso.O)),
+	val, err := store.pso.UpdateWith(ctx,
+		ddb.Updater(pso, _pso.Minus(pso.O), _psoK.Minus(pso.K)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if len(val.O) == 0 {
+		_, err = store.pso.Remove(ctx, pso, _psoC.NotExists())
+		if !isPreConditionFailed(err) {
+			return err
+		}
+	}
+
+	// k-order is unknown if the statement is not read from the table
+	if ks := selectK(val.K, pso.O[0]); len(ks) != 0 {
+		_, err = store.pso.UpdateWith(ctx,
+			ddb.Updater(pso, _psoK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 var (
-	_pso = ddb.UpdateFor[pso, []string]()
+	_pso  = ddb.UpdateFor[pso, []string]()
+	_psoK = ddb.UpdateFor[pso, kset]()
+	_psoC = ddb.ClauseFor[pso, []string]()
 )
 
-func encodePSO(g curie.IRI, spock hexer.SPOCK) pso {
+func encodePSO(g curie.IRI, spock hexer.SPOCK) (pso, error) {
+	o, err := encodeValue(spock.O)
+	if err != nil {
+		return pso{}, err
+	}
+
 	return pso{
 		G:  "ps|" + g,
 		PS: encodeII(spock.P, spock.S),
-		O:  []string{encodeValue(spock.O)},
-	}
+		O:  []string{o},
+		K:  encodeK(spock.K, o),
+	}, nil
 }
 
 func decodePSO(pso pso) []hexer.SPOCK {
+	g := decodeG(pso.G)
 	seq := make([]hexer.SPOCK, len(pso.O))
 	p, s := decodeII(pso.PS)
+	ks := decodeK(pso.K)
 
 	for i, o := range pso.O {
-		seq[i].S, seq[i].P, seq[i].O = s, p, decodeValue(o)
+		seq[i].G, seq[i].S, seq[i].P, seq[i].O = g, s, p, decodeValue(o)
+		seq[i].K = ks[o]
 	}
 
 	return seq
@@ -225,44 +458,98 @@ type osp struct {
 	G  curie.IRI   `dynamodbav:"prefix"`
 	OS string      `dynamodbav:"suffix"`
 	P  []curie.IRI `dynamodbav:"p,stringset"`
+	K  kset        `dynamodbav:"k,stringset,omitempty"`
 }
 
 func (osp osp) HashKey() curie.IRI     { return osp.G }
 func (osp osp) SortKey() curie.IRI     { return curie.IRI(osp.OS) }
 func (osp osp) ToSPOCK() []hexer.SPOCK { return decodeOSP(osp) }
 
-func (osp osp) Put(ctx context.Context, store *Store) error {
+func (osp osp) Put(ctx context.Context, store *Store) (bool, error) {
 	_, err := store.osp.UpdateWith(ctx,
-		ddb.Updater(osp, _osp.Union(osp.P)),
+		ddb.Updater(osp, _osp.Union(osp.P), _ospK.Union(osp.K)),
+		_ospC.NotContains(osp.P[0]),
+	)
+	if isPreConditionFailed(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (osp osp) PutK(ctx context.Context, store *Store) error {
+	val, err := store.osp.UpdateWith(ctx,
+		ddb.Updater(osp, _ospK.Union(osp.K)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if ks := laterK(val.K, string(osp.P[0])); len(ks) != 0 {
+		_, err = store.osp.UpdateWith(ctx,
+			ddb.Updater(osp, _ospK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 func (osp osp) Cut(ctx context.Context, store *Store) error {
-	_, err := store.osp.UpdateWith(ctx,
-		ddb.Updater(osp, _osp.Minus(osp.P)),
+	val, err := store.osp.UpdateWith(ctx,
+		ddb.Updater(osp, _osp.Minus(osp.P), _ospK.Minus(osp.K)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if len(val.P) == 0 {
+		_, err = store.osp.Remove(ctx, osp, _ospC.NotExists())
+		if !isPreConditionFailed(err) {
+			return err
+		}
+	}
+
+	// k-order is unknown if the statement is not read from the table
+	if ks := selectK(val.K, string(osp.P[0])); len(ks) != 0 {
+		_, err = store.osp.UpdateWith(ctx,
+			ddb.Updater(osp, _ospK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 var (
-	_osp = ddb.UpdateFor[osp, []curie.IRI]()
+	_osp  = ddb.UpdateFor[osp, []curie.IRI]()
+	_ospK = ddb.UpdateFor[osp, kset]()
+	_ospC = ddb.ClauseFor[osp, []curie.IRI]()
 )
 
-func encodeOSP(g curie.IRI, spock hexer.SPOCK) osp {
+func encodeOSP(g curie.IRI, spock hexer.SPOCK) (osp, error) {
+	os, err := encodeVI(spock.O, spock.S)
+	if err != nil {
+		return osp{}, err
+	}
+
 	return osp{
 		G:  "os|" + g,
-		OS: encodeVI(spock.O, spock.S),
+		OS: os,
 		P:  []curie.IRI{spock.P},
-	}
+		K:  encodeK(spock.K, string(spock.P)),
+	}, nil
 }
 
 func decodeOSP(osp osp) []hexer.SPOCK {
+	g := decodeG(osp.G)
 	seq := make([]hexer.SPOCK, len(osp.P))
 	o, s := decodeVI(osp.OS)
+	ks := decodeK(osp.K)
 
 	for i, p := range osp.P {
-		seq[i].S, seq[i].P, seq[i].O = s, p, o
+		seq[i].G, seq[i].S, seq[i].P, seq[i].O = g, s, p, o
+		seq[i].K = ks[string(p)]
 	}
 
 	return seq
@@ -276,44 +563,98 @@ type ops struct {
 	G  curie.IRI   `dynamodbav:"prefix"`
 	OP string      `dynamodbav:"suffix"`
 	S  []curie.IRI `dynamodbav:"s,stringset"`
+	K  kset        `dynamodbav:"k,stringset,omitempty"`
 }
 
 func (ops ops) HashKey() curie.IRI     { return ops.G }
 func (ops ops) SortKey() curie.IRI     { return curie.IRI(ops.OP) }
 func (ops ops) ToSPOCK() []hexer.SPOCK { return decodeOPS(ops) }
 
-func (ops ops) Put(ctx context.Context, store *Store) error {
+func (ops ops) Put(ctx context.Context, store *Store) (bool, error) {
 	_, err := store.ops.UpdateWith(ctx,
-		ddb.Updater(ops, _ops.Union(ops.S)),
+		ddb.Updater(ops, _ops.Union(ops.S), _opsK.Union(ops.K)),
+		_opsC.NotContains(ops.S[0]),
 	)
-	return err
+	if isPreConditionFailed(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (ops ops) PutK(ctx context.Context, store *Store) error {
+	val, err := store.ops.UpdateWith(ctx,
+		ddb.Updater(ops, _opsK.Union(ops.K)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if ks := laterK(val.K, string(ops.S[0])); len(ks) != 0 {
+		_, err = store.ops.UpdateWith(ctx,
+			ddb.Updater(ops, _opsK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 func (ops ops) Cut(ctx context.Context, store *Store) error {
-	_, err := store.ops.UpdateWith(ctx,
-		ddb.Updater(ops, _ops.Minus(ops.S)),
+	val, err := store.ops.UpdateWith(ctx,
+		ddb.Updater(ops, _ops.Minus(ops.S), _opsK.Minus(ops.K)),
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if len(val.S) == 0 {
+		_, err = store.ops.Remove(ctx, ops, _opsC.NotExists())
+		if !isPreConditionFailed(err) {
+			return err
+		}
+	}
+
+	// k-order is unknown if the statement is not read from the table
+	if ks := selectK(val.K, string(ops.S[0])); len(ks) != 0 {
+		_, err = store.ops.UpdateWith(ctx,
+			ddb.Updater(ops, _opsK.Minus(ks)),
+		)
+		return err
+	}
+
+	return nil
 }
 
 var (
-	_ops = ddb.UpdateFor[ops, []curie.IRI]()
+	_ops  = ddb.UpdateFor[ops, []curie.IRI]()
+	_opsK = ddb.UpdateFor[ops, kset]()
+	_opsC = ddb.ClauseFor[ops, []curie.IRI]()
 )
 
-func encodeOPS(g curie.IRI, spock hexer.SPOCK) ops {
+func encodeOPS(g curie.IRI, spock hexer.SPOCK) (ops, error) {
+	op, err := encodeVI(spock.O, spock.P)
+	if err != nil {
+		return ops{}, err
+	}
+
 	return ops{
 		G:  "op|" + g,
-		OP: encodeVI(spock.O, spock.P),
+		OP: op,
 		S:  []curie.IRI{spock.S},
-	}
+		K:  encodeK(spock.K, string(spock.S)),
+	}, nil
 }
 
 func decodeOPS(ops ops) []hexer.SPOCK {
+	g := decodeG(ops.G)
 	seq := make([]hexer.SPOCK, len(ops.S))
 	o, p := decodeVI(ops.OP)
+	ks := decodeK(ops.K)
 
 	for i, s := range ops.S {
-		seq[i].S, seq[i].P, seq[i].O = s, p, o
+		seq[i].G, seq[i].S, seq[i].P, seq[i].O = g, s, p, o
+		seq[i].K = ks[string(s)]
 	}
 
 	return seq
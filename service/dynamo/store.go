@@ -2,10 +2,12 @@ package dynamo
 
 import (
 	"context"
+	"errors"
 
 	"github.com/fogfish/curie"
 	"github.com/fogfish/dynamo/v2"
 	"github.com/fogfish/dynamo/v2/service/ddb"
+	"github.com/fogfish/guid/v2"
 	"github.com/fogfish/hexer"
 )
 
@@ -69,25 +71,89 @@ func Add(ctx context.Context, store *Store, bag hexer.Bag) (hexer.Bag, error) {
 	return nil, nil
 }
 
+func writers(g curie.IRI, spock hexer.SPOCK) ([]Writer, error) {
+	spo, err := encodeSPO(g, spock)
+	if err != nil {
+		return nil, err
+	}
+
+	sop, err := encodeSOP(g, spock)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := encodePOS(g, spock)
+	if err != nil {
+		return nil, err
+	}
+
+	pso, err := encodePSO(g, spock)
+	if err != nil {
+		return nil, err
+	}
+
+	ops, err := encodeOPS(g, spock)
+	if err != nil {
+		return nil, err
+	}
+
+	osp, err := encodeOSP(g, spock)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Writer{spo, sop, pos, pso, ops, osp}, nil
+}
+
+// Put knowledge statement into all indexes. The statement is stamped with
+// k-order unless it is supplied by caller. The earliest k-order is kept
+// if the statement is re-inserted.
 func Put(ctx context.Context, store *Store, spock hexer.SPOCK) error {
-	g := curie.IRI("a")
+	// the stamp is later than k-order of existing statement
+	stamped := spock.K == (guid.K{})
+	if stamped {
+		spock.K = guid.L(guid.Clock)
+	}
 
-	seq := []Writer{
-		encodeSPO(g, spock),
-		encodeSOP(g, spock),
-		encodePOS(g, spock),
-		encodePSO(g, spock),
-		encodeOPS(g, spock),
-		encodeOSP(g, spock),
+	seq, err := writers(encodeG(spock.G), spock)
+	if err != nil {
+		return err
 	}
 
-	for i := 0; i < len(seq); i++ {
-		if err := seq[i].Put(ctx, store); err != nil {
-			for k := 0; k < i; k++ {
-				if err := seq[k].Cut(ctx, store); err != nil {
-					// TODO: log error
-				}
+	// values existed before are not rolled back, Cut would remove them
+	created := make([]Writer, 0, len(seq))
+	for _, w := range seq {
+		isNew, err := w.Put(ctx, store)
+		if err == nil && !isNew && !stamped {
+			err = w.PutK(ctx, store)
+		}
+
+		if err != nil {
+			errs := []error{err}
+			for _, c := range created {
+				errs = append(errs, c.Cut(ctx, store))
 			}
+			return errors.Join(errs...)
+		}
+
+		if isNew {
+			created = append(created, w)
+		}
+	}
+
+	return nil
+}
+
+// Cut removes knowledge statement from all indexes. The operation is
+// idempotent, it is safe to repeat it if some of indexes has failed.
+func Cut(ctx context.Context, store *Store, spock hexer.SPOCK) error {
+	seq, err := writers(encodeG(spock.G), spock)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(seq); i++ {
+		if err := seq[i].Cut(ctx, store); err != nil {
 			return err
 		}
 	}
@@ -95,8 +161,42 @@ func Put(ctx context.Context, store *Store, spock hexer.SPOCK) error {
 	return nil
 }
 
+// CutMatch removes all knowledge statements matching the pattern.
+// It returns statements that are not removed due to failure.
+func CutMatch(ctx context.Context, store *Store, q hexer.Pattern) (hexer.Bag, error) {
+	seq, err := Match(ctx, store, q)
+	if err != nil {
+		return nil, err
+	}
+
+	// indexes are not modified while the stream is consumed
+	bag := hexer.Bag{}
+	if err := seq.FMap(bag.Join); err != nil {
+		return nil, err
+	}
+
+	for i, spock := range bag {
+		if err := Cut(ctx, store, spock); err != nil {
+			return bag[i:], err
+		}
+	}
+
+	return nil, nil
+}
+
 func Match(ctx context.Context, store *Store, q hexer.Pattern) (hexer.Stream, error) {
+	if q.G != nil && q.G.Clause != hexer.EQ {
+		return nil, &notSupported{q}
+	}
+
+	if err := validate(q.O); err != nil {
+		return nil, err
+	}
+
 	switch q.Strategy {
+	case hexer.STRATEGY_NONE:
+		// full scan of the graph, sp|g partition holds every statement
+		return store.streamSPO(ctx, q)
 	case hexer.STRATEGY_SPO:
 		return store.streamSPO(ctx, q)
 	case hexer.STRATEGY_SOP:
@@ -110,10 +210,38 @@ func Match(ctx context.Context, store *Store, q hexer.Pattern) (hexer.Stream, er
 	case hexer.STRATEGY_OPS:
 		return store.streamOPS(ctx, q)
 	default:
-		panic("xxx")
+		return nil, &notSupported{q}
 	}
 }
 
+//------------------------------------------------------------------------------
+//
+// hexer.Store interface
+//
+//------------------------------------------------------------------------------
+
+var _ hexer.Store = (*Store)(nil)
+
+// Put knowledge statement into the store
+func (store *Store) Put(ctx context.Context, spock hexer.SPOCK) error {
+	return Put(ctx, store, spock)
+}
+
+// Add bag of knowledge statements into the store
+func (store *Store) Add(ctx context.Context, bag hexer.Bag) (hexer.Bag, error) {
+	return Add(ctx, store, bag)
+}
+
+// Cut knowledge statement from the store
+func (store *Store) Cut(ctx context.Context, spock hexer.SPOCK) error {
+	return Cut(ctx, store, spock)
+}
+
+// Match knowledge statements with the pattern
+func (store *Store) Match(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
+	return Match(ctx, store, q)
+}
+
 // func Get(ctx context.Context, store *Store, spock hexer.SPOCK[string]) SPO {
 // 	key := SPO{
 // 		G:  "g:a",
@@ -6,6 +6,7 @@ import (
 
 	"github.com/fogfish/curie"
 	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
 )
 
 type notSupported struct{ hexer.Pattern }
@@ -13,11 +14,40 @@ type notSupported struct{ hexer.Pattern }
 func (err notSupported) Error() string { return fmt.Sprintf("not supported %s", err.Pattern.Dump()) }
 func (notSupported) NotSupported()     {}
 
+// graph of the pattern, nil graph predicate is the default graph
+func graphOf(q hexer.Pattern) curie.IRI {
+	if q.G == nil {
+		return defaultGraph
+	}
+
+	return encodeG(q.G.Value)
+}
+
+// values of the predicate are compared by filters, the data-type must be known
+func validate(pred *hexer.Predicate[xsd.Value]) error {
+	if pred == nil {
+		return nil
+	}
+
+	for _, v := range []xsd.Value{pred.Value, pred.Other} {
+		if v != nil {
+			if err := xsd.Validate(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (store *Store) streamSPO(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
-	g := curie.IRI("a")
+	g := graphOf(q)
 	key := spo{G: "sp|" + g}
 
 	switch {
+	case q.HintForS == hexer.HINT_NONE && q.HintForP == hexer.HINT_NONE:
+		// full scan of partition, sort key is not constrained
+		key.SP = ""
 	case q.HintForS == hexer.HINT_MATCH && q.HintForP == hexer.HINT_NONE:
 		key.SP = encodeII(q.S.Value, "")
 	case q.HintForS == hexer.HINT_MATCH && q.HintForP == hexer.HINT_MATCH:
@@ -31,7 +61,7 @@ func (store *Store) streamSPO(ctx context.Context, q hexer.Pattern) (hexer.Strea
 	}
 
 	var stream hexer.Stream = &Unfold[spo]{
-		seq: NewIterator(store.spo, key),
+		seq: NewIterator(ctx, store.spo, key),
 	}
 
 	if q.O != nil {
@@ -42,24 +72,35 @@ func (store *Store) streamSPO(ctx context.Context, q hexer.Pattern) (hexer.Strea
 }
 
 func (store *Store) streamSOP(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
-	g := curie.IRI("a")
+	g := graphOf(q)
 	key := sop{G: "so|" + g}
+	var err error
 
 	switch {
 	case q.HintForS == hexer.HINT_MATCH && q.HintForO == hexer.HINT_NONE:
 		key.SO = encodeII(q.S.Value, "")
 	case q.HintForS == hexer.HINT_MATCH && q.HintForO == hexer.HINT_MATCH:
-		key.SO = encodeIV(q.S.Value, q.O.Value)
+		key.SO, err = encodeIV(q.S.Value, q.O.Value)
 	case q.HintForS == hexer.HINT_MATCH && q.HintForO == hexer.HINT_FILTER_PREFIX:
-		key.SO = encodeIV(q.S.Value, q.O.Value)
+		key.SO, err = encodeIV(q.S.Value, q.O.Value)
+	case q.HintForS == hexer.HINT_MATCH && q.HintForO == hexer.HINT_FILTER:
+		key.SO = encodeII(q.S.Value, "")
 	case q.HintForS == hexer.HINT_FILTER_PREFIX && q.HintForO == hexer.HINT_NONE:
 		key.SO = encodeI(q.S.Value)
 	default:
 		return nil, &notSupported{q}
 	}
 
+	if err != nil {
+		return nil, err
+	}
+
 	var stream hexer.Stream = &Unfold[sop]{
-		seq: NewIterator(store.sop, key),
+		seq: NewIterator(ctx, store.sop, key),
+	}
+
+	if q.HintForO == hexer.HINT_FILTER {
+		stream = hexer.NewFilterO(q.HintForO, q.O, stream)
 	}
 
 	if q.P != nil {
@@ -70,7 +111,7 @@ func (store *Store) streamSOP(ctx context.Context, q hexer.Pattern) (hexer.Strea
 }
 
 func (store *Store) streamPSO(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
-	g := curie.IRI("a")
+	g := graphOf(q)
 	key := pso{G: "ps|" + g}
 
 	switch {
@@ -87,7 +128,7 @@ func (store *Store) streamPSO(ctx context.Context, q hexer.Pattern) (hexer.Strea
 	}
 
 	var stream hexer.Stream = &Unfold[pso]{
-		seq: NewIterator(store.pso, key),
+		seq: NewIterator(ctx, store.pso, key),
 	}
 
 	if q.O != nil {
@@ -98,24 +139,35 @@ func (store *Store) streamPSO(ctx context.Context, q hexer.Pattern) (hexer.Strea
 }
 
 func (store *Store) streamPOS(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
-	g := curie.IRI("a")
+	g := graphOf(q)
 	key := pos{G: "po|" + g}
+	var err error
 
 	switch {
 	case q.HintForP == hexer.HINT_MATCH && q.HintForO == hexer.HINT_NONE:
 		key.PO = encodeII(q.P.Value, "")
 	case q.HintForP == hexer.HINT_MATCH && q.HintForO == hexer.HINT_MATCH:
-		key.PO = encodeIV(q.P.Value, q.O.Value)
+		key.PO, err = encodeIV(q.P.Value, q.O.Value)
 	case q.HintForP == hexer.HINT_MATCH && q.HintForO == hexer.HINT_FILTER_PREFIX:
-		key.PO = encodeIV(q.P.Value, q.O.Value)
+		key.PO, err = encodeIV(q.P.Value, q.O.Value)
+	case q.HintForP == hexer.HINT_MATCH && q.HintForO == hexer.HINT_FILTER:
+		key.PO = encodeII(q.P.Value, "")
 	case q.HintForP == hexer.HINT_FILTER_PREFIX && q.HintForO == hexer.HINT_NONE:
 		key.PO = encodeI(q.P.Value)
 	default:
 		return nil, &notSupported{q}
 	}
 
+	if err != nil {
+		return nil, err
+	}
+
 	var stream hexer.Stream = &Unfold[pos]{
-		seq: NewIterator(store.pos, key),
+		seq: NewIterator(ctx, store.pos, key),
+	}
+
+	if q.HintForO == hexer.HINT_FILTER {
+		stream = hexer.NewFilterO(q.HintForO, q.O, stream)
 	}
 
 	if q.S != nil {
@@ -126,24 +178,29 @@ func (store *Store) streamPOS(ctx context.Context, q hexer.Pattern) (hexer.Strea
 }
 
 func (store *Store) streamOSP(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
-	g := curie.IRI("a")
+	g := graphOf(q)
 	key := osp{G: "os|" + g}
+	var err error
 
 	switch {
 	case q.HintForO == hexer.HINT_MATCH && q.HintForS == hexer.HINT_NONE:
-		key.OS = encodeVI(q.O.Value, "")
+		key.OS, err = encodeVI(q.O.Value, "")
 	case q.HintForO == hexer.HINT_MATCH && q.HintForS == hexer.HINT_MATCH:
-		key.OS = encodeVI(q.O.Value, q.S.Value)
+		key.OS, err = encodeVI(q.O.Value, q.S.Value)
 	case q.HintForO == hexer.HINT_MATCH && q.HintForS == hexer.HINT_FILTER_PREFIX:
-		key.OS = encodeVI(q.O.Value, q.S.Value)
+		key.OS, err = encodeVI(q.O.Value, q.S.Value)
 	case q.HintForO == hexer.HINT_FILTER_PREFIX && q.HintForS == hexer.HINT_NONE:
-		key.OS = encodeValue(q.O.Value)
+		key.OS, err = encodeValue(q.O.Value)
 	default:
 		return nil, &notSupported{q}
 	}
 
+	if err != nil {
+		return nil, err
+	}
+
 	var stream hexer.Stream = &Unfold[osp]{
-		seq: NewIterator(store.osp, key),
+		seq: NewIterator(ctx, store.osp, key),
 	}
 
 	if q.P != nil {
@@ -154,24 +211,29 @@ func (store *Store) streamOSP(ctx context.Context, q hexer.Pattern) (hexer.Strea
 }
 
 func (store *Store) streamOPS(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
-	g := curie.IRI("a")
+	g := graphOf(q)
 	key := ops{G: "op|" + g}
+	var err error
 
 	switch {
 	case q.HintForO == hexer.HINT_MATCH && q.HintForP == hexer.HINT_NONE:
-		key.OP = encodeVI(q.O.Value, "")
+		key.OP, err = encodeVI(q.O.Value, "")
 	case q.HintForO == hexer.HINT_MATCH && q.HintForP == hexer.HINT_MATCH:
-		key.OP = encodeVI(q.O.Value, q.P.Value)
+		key.OP, err = encodeVI(q.O.Value, q.P.Value)
 	case q.HintForO == hexer.HINT_MATCH && q.HintForP == hexer.HINT_FILTER_PREFIX:
-		key.OP = encodeVI(q.O.Value, q.P.Value)
+		key.OP, err = encodeVI(q.O.Value, q.P.Value)
 	case q.HintForO == hexer.HINT_FILTER_PREFIX && q.HintForP == hexer.HINT_NONE:
-		key.OP = encodeValue(q.O.Value)
+		key.OP, err = encodeValue(q.O.Value)
 	default:
 		return nil, &notSupported{q}
 	}
 
+	if err != nil {
+		return nil, err
+	}
+
 	var stream hexer.Stream = &Unfold[ops]{
-		seq: NewIterator(store.ops, key),
+		seq: NewIterator(ctx, store.ops, key),
 	}
 
 	if q.S != nil {
@@ -0,0 +1,425 @@
+package ephemeral
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+	"github.com/fogfish/skiplist"
+)
+
+//
+// The file implements binary format of the store. The format is
+//
+//	magic    "HEXR"
+//	version  uvarint
+//	types    uvarint n, n × string                   -- data types of terms
+//	terms    uvarint n, n × (uvarint type, string)   -- lexical forms
+//	facts    uvarint n, n × (uvarint g, s, p, o, float64 c, k)
+//
+// Strings are prefixed by uvarint length. Terms are referenced by position,
+// starting from 1, the reference 0 is the default graph. IRIs are terms of
+// xsd:anyURI type, language-tagged strings are "text@lang". Statements are
+// sorted by graph and ⟨s,p,o⟩. The k-order is uvarint Hi, Lo and local flag.
+// Multi-byte numbers are little-endian.
+//
+
+const (
+	magic   = "HEXR"
+	version = 1
+)
+
+// max length of string, it protects decoder from corrupted files
+const maxString = 1 << 26
+
+var errFormat = errors.New("ephemeral: invalid file format")
+
+// identity of the term in the dictionary
+type term struct {
+	dt  curie.IRI
+	lex string
+}
+
+func termOf(v xsd.Value) (term, error) {
+	if lang, ok := v.(xsd.LangString); ok {
+		return term{dt: xsd.RDF_LANGSTRING, lex: lang.Value + "@" + lang.Lang}, nil
+	}
+
+	lex, err := xsd.Lexical(v)
+	if err != nil {
+		return term{}, err
+	}
+
+	return term{dt: v.XSDType(), lex: lex}, nil
+}
+
+func (t term) value() (xsd.Value, error) {
+	if t.dt == xsd.RDF_LANGSTRING {
+		at := strings.LastIndexByte(t.lex, '@')
+		if at == -1 {
+			return nil, errFormat
+		}
+		return xsd.Lang(t.lex[:at], t.lex[at+1:]), nil
+	}
+
+	return xsd.Parse(t.dt, t.lex)
+}
+
+// Save writes all statements of the store in binary format. Writers are
+// blocked until statements are written, use Save of the Snapshot so that
+// writers are not blocked at the cost of copying lists on write until the
+// view is released.
+func Save(store *Store, w io.Writer) error {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	return save(store, w)
+}
+
+// Save writes all statements of the view in binary format
+func (view *View) Save(w io.Writer) error {
+	return save(view.store, w)
+}
+
+func save(store *Store, w io.Writer) error {
+	graphs := make([]curie.IRI, 0, len(store.graphs))
+	for g := range store.graphs {
+		graphs = append(graphs, g)
+	}
+	sort.Slice(graphs, func(i, j int) bool { return graphs[i] < graphs[j] })
+
+	walk := func(f func(hexer.SPOCK) error) error {
+		for _, g := range graphs {
+			err := skiplist.Values(store.graphs[g].spo.SkipList).FMap(func(s s, _po _po) error {
+				return skiplist.Values(_po.SkipList).FMap(func(p p, __o __o) error {
+					return skiplist.Values(__o.SkipList).FMap(func(o o, ck ck) error {
+						return f(hexer.SPOCK{G: g, S: s, P: p, O: o, C: ck.C, K: ck.K})
+					})
+				})
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// the dictionary of terms and data types in the order of use
+	types := map[curie.IRI]uint64{}
+	terms := map[term]uint64{}
+	dict := []term{}
+
+	define := func(v xsd.Value) error {
+		t, err := termOf(v)
+		if err != nil {
+			return err
+		}
+
+		if _, has := terms[t]; !has {
+			dict = append(dict, t)
+			terms[t] = uint64(len(dict))
+		}
+		if _, has := types[t.dt]; !has {
+			types[t.dt] = uint64(len(types))
+		}
+		return nil
+	}
+
+	err := walk(func(spock hexer.SPOCK) error {
+		if spock.G != "" {
+			if err := define(xsd.AnyURI(spock.G)); err != nil {
+				return err
+			}
+		}
+		if err := define(xsd.AnyURI(spock.S)); err != nil {
+			return err
+		}
+		if err := define(xsd.AnyURI(spock.P)); err != nil {
+			return err
+		}
+		return define(spock.O)
+	})
+	if err != nil {
+		return err
+	}
+
+	buf := bufio.NewWriter(w)
+	enc := &encoder{w: buf}
+	enc.w.WriteString(magic)
+	enc.uvarint(version)
+
+	dts := make([]curie.IRI, len(types))
+	for dt, i := range types {
+		dts[i] = dt
+	}
+
+	enc.uvarint(uint64(len(dts)))
+	for _, dt := range dts {
+		enc.string(string(dt))
+	}
+
+	enc.uvarint(uint64(len(dict)))
+	for _, t := range dict {
+		enc.uvarint(types[t.dt])
+		enc.string(t.lex)
+	}
+
+	enc.uvarint(uint64(store.size))
+	err = walk(func(spock hexer.SPOCK) error {
+		g := uint64(0)
+		if spock.G != "" {
+			g = terms[term{dt: xsd.XSD_ANYURI, lex: string(spock.G)}]
+		}
+		o, _ := termOf(spock.O)
+
+		enc.uvarint(g)
+		enc.uvarint(terms[term{dt: xsd.XSD_ANYURI, lex: string(spock.S)}])
+		enc.uvarint(terms[term{dt: xsd.XSD_ANYURI, lex: string(spock.P)}])
+		enc.uvarint(terms[o])
+		enc.float64(spock.C)
+		enc.uvarint(spock.K.Hi)
+		enc.uvarint(spock.K.Lo)
+		enc.bool(spock.K.Local)
+		return enc.err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	return enc.err
+}
+
+// Load creates new store from statements written in binary format.
+// User-defined data types must be registered before the load.
+func Load(r io.Reader, opts ...Option) (*Store, error) {
+	dec := &decoder{r: bufio.NewReader(r)}
+
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(dec.r, head); err != nil || string(head) != magic {
+		return nil, errFormat
+	}
+
+	if v := dec.uvarint(); dec.err == nil && v != version {
+		return nil, fmt.Errorf("ephemeral: unsupported file version %d", v)
+	}
+
+	n := dec.length()
+	dts := []curie.IRI{}
+	for i := 0; i < n && dec.err == nil; i++ {
+		dts = append(dts, curie.IRI(dec.string()))
+	}
+
+	n = dec.length()
+	dict := []xsd.Value{nil}
+	for i := 0; i < n && dec.err == nil; i++ {
+		dt := dec.uvarint()
+		lex := dec.string()
+		if dec.err != nil {
+			break
+		}
+
+		if dt >= uint64(len(dts)) {
+			return nil, errFormat
+		}
+
+		v, err := term{dt: dts[dt], lex: lex}.value()
+		if err != nil {
+			return nil, fmt.Errorf("ephemeral: %w", err)
+		}
+		dict = append(dict, v)
+	}
+
+	iri := func(id uint64, allowDefault bool) (curie.IRI, error) {
+		if id == 0 && allowDefault {
+			return "", nil
+		}
+		if id == 0 || id >= uint64(len(dict)) {
+			return "", errFormat
+		}
+		v, ok := dict[id].(xsd.AnyURI)
+		if !ok {
+			return "", errFormat
+		}
+		return curie.IRI(v), nil
+	}
+
+	store := New(opts...)
+
+	n = dec.length()
+	for i := 0; i < n && dec.err == nil; i++ {
+		g, s, p, o := dec.uvarint(), dec.uvarint(), dec.uvarint(), dec.uvarint()
+		c := dec.float64()
+		k := guid.K{Hi: dec.uvarint(), Lo: dec.uvarint(), Local: dec.bool()}
+		if dec.err != nil {
+			break
+		}
+
+		spock := hexer.SPOCK{C: c, K: k}
+		var err error
+		if spock.G, err = iri(g, true); err != nil {
+			return nil, err
+		}
+		if spock.S, err = iri(s, false); err != nil {
+			return nil, err
+		}
+		if spock.P, err = iri(p, false); err != nil {
+			return nil, err
+		}
+		if o == 0 || o >= uint64(len(dict)) {
+			return nil, errFormat
+		}
+		spock.O = dict[o]
+
+		graph := store.ensureGraph(spock.G)
+		if !exists(graph, spock) {
+			index(store, graph, spock)
+			store.size++
+		}
+	}
+
+	if dec.err != nil {
+		return nil, dec.err
+	}
+
+	return store, nil
+}
+
+//------------------------------------------------------------------------------
+
+type encoder struct {
+	w interface {
+		io.Writer
+		io.StringWriter
+	}
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (enc *encoder) write(b []byte) {
+	if enc.err == nil {
+		_, enc.err = enc.w.Write(b)
+	}
+}
+
+func (enc *encoder) uvarint(v uint64) {
+	n := binary.PutUvarint(enc.buf[:], v)
+	enc.write(enc.buf[:n])
+}
+
+func (enc *encoder) string(s string) {
+	enc.uvarint(uint64(len(s)))
+	if enc.err == nil {
+		_, enc.err = enc.w.WriteString(s)
+	}
+}
+
+func (enc *encoder) float64(v float64) {
+	binary.LittleEndian.PutUint64(enc.buf[:8], math.Float64bits(v))
+	enc.write(enc.buf[:8])
+}
+
+func (enc *encoder) bool(v bool) {
+	if v {
+		enc.buf[0] = 1
+	} else {
+		enc.buf[0] = 0
+	}
+	enc.write(enc.buf[:1])
+}
+
+type decoder struct {
+	r interface {
+		io.Reader
+		io.ByteReader
+	}
+	buf [8]byte
+	err error
+}
+
+func (dec *decoder) fail(err error) {
+	if dec.err != nil {
+		return
+	}
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = errFormat
+	}
+	dec.err = err
+}
+
+func (dec *decoder) uvarint() uint64 {
+	if dec.err != nil {
+		return 0
+	}
+
+	v, err := binary.ReadUvarint(dec.r)
+	if err != nil {
+		dec.fail(err)
+	}
+	return v
+}
+
+// length of sequence, it is not used to allocate memory
+func (dec *decoder) length() int {
+	n := dec.uvarint()
+	if n > math.MaxInt32 {
+		dec.fail(errFormat)
+		return 0
+	}
+	return int(n)
+}
+
+func (dec *decoder) string() string {
+	n := dec.uvarint()
+	if n > maxString {
+		dec.fail(errFormat)
+	}
+	if dec.err != nil {
+		return ""
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(dec.r, b); err != nil {
+		dec.fail(err)
+		return ""
+	}
+	return string(b)
+}
+
+func (dec *decoder) float64() float64 {
+	if dec.err != nil {
+		return 0
+	}
+
+	if _, err := io.ReadFull(dec.r, dec.buf[:8]); err != nil {
+		dec.fail(err)
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(dec.buf[:8]))
+}
+
+func (dec *decoder) bool() bool {
+	if dec.err != nil {
+		return false
+	}
+
+	b, err := dec.r.ReadByte()
+	if err != nil {
+		dec.fail(err)
+		return false
+	}
+	return b != 0
+}
@@ -1,13 +1,22 @@
 package ephemeral_test
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/fogfish/curie"
+	"github.com/fogfish/guid/v2"
 	"github.com/fogfish/hexer"
 	"github.com/fogfish/hexer/service/ephemeral"
+	"github.com/fogfish/hexer/sparql"
+	"github.com/fogfish/hexer/xsd"
 	"github.com/fogfish/it/v2"
 )
 
@@ -51,6 +60,20 @@ func setup(bag hexer.Bag) *ephemeral.Store {
 	return store
 }
 
+// collects statements of the stream. The k-order is assigned by the store,
+// it is checked and dropped so that statements are comparable with dataset.
+func joinK(t *testing.T, bag *hexer.Bag) func(hexer.SPOCK) error {
+	return func(spock hexer.SPOCK) error {
+		t.Helper()
+		it.Then(t).ShouldNot(
+			it.Equal(spock.K, guid.K{}),
+		)
+
+		spock.K = guid.K{}
+		return bag.Join(spock)
+	}
+}
+
 func TestSocialGraph(t *testing.T) {
 	rds := setup(datasetSocialGraph())
 
@@ -60,7 +83,7 @@ func TestSocialGraph(t *testing.T) {
 		seq, err := ephemeral.Match(rds, req)
 		it.Then(t).Should(it.Nil(err))
 
-		err = seq.FMap(bag.Join)
+		err = seq.FMap(joinK(t, &bag))
 		it.Then(t).Should(
 			it.Nil(err),
 			it.Equal(req.String(), uid),
@@ -69,6 +92,30 @@ func TestSocialGraph(t *testing.T) {
 		return it.Seq(bag)
 	}
 
+	//
+	// #1: (___) ⇒ ∅
+	//
+	t.Run("#1: (___) ⇒ ∅", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t, "(___) ⇒ ∅",
+				hexer.Query(nil, nil, nil),
+			).Equal(
+				hexer.From(C, "follows", B),
+				hexer.From(C, "follows", E),
+				hexer.From(C, "relates", D),
+				hexer.From(F, "follows", G),
+				hexer.From(G, "status", "g"),
+				hexer.From(A, "follows", B),
+				hexer.From(B, "follows", F),
+				hexer.From(B, "status", "b"),
+				hexer.From(D, "relates", G),
+				hexer.From(D, "relates", B),
+				hexer.From(D, "status", "d"),
+				hexer.From(E, "follows", F),
+			),
+		)
+	})
+
 	//
 	// #2: (s) ⇒ po
 	//
@@ -1100,3 +1147,1386 @@ func TestSocialGraph(t *testing.T) {
 	})
 
 }
+
+// collects statements matching the pattern, see joinK
+func match(t *testing.T, store hexer.Getter, q hexer.Pattern) hexer.Bag {
+	t.Helper()
+	bag := hexer.Bag{}
+	seq, err := store.Match(context.Background(), q)
+	it.Then(t).Should(it.Nil(err))
+	if err == nil {
+		it.Then(t).Should(it.Nil(seq.FMap(joinK(t, &bag))))
+	}
+
+	return bag
+}
+
+// query and statements it matches
+type matchCase struct {
+	name  string
+	query hexer.Pattern
+	bag   hexer.Bag
+}
+
+// runs each case as subtest over the store
+func testMatch(t *testing.T, store hexer.Getter, cases []matchCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			it.Then(t).Should(
+				it.Seq(match(t, store, tc.query)).Equal(tc.bag...),
+			)
+		})
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	rds := setup(hexer.Bag{
+		hexer.From(A, "age", 30),
+		hexer.From(B, "age", 25.5),
+		hexer.From(C, "age", uint8(40)),
+		hexer.From(D, "age", "unknown"),
+		hexer.From(E, "rank", xsd.Decimal(27.1)),
+	})
+
+	testMatch(t, rds, []matchCase{
+		{
+			name:  "Eq",
+			query: hexer.Query(nil, hexer.IRI.Equal("age"), hexer.Eq(30)),
+			bag:   hexer.Bag{hexer.From(A, "age", 30)},
+		},
+		{
+			name:  "Lt",
+			query: hexer.Query(nil, hexer.IRI.Equal("age"), hexer.Lt(30)),
+			bag:   hexer.Bag{hexer.From(B, "age", 25.5)},
+		},
+		{
+			name:  "Gt",
+			query: hexer.Query(nil, hexer.IRI.Equal("age"), hexer.Gt(26.0)),
+			bag: hexer.Bag{
+				hexer.From(A, "age", 30),
+				hexer.From(C, "age", uint8(40)),
+			},
+		},
+		{
+			name:  "In",
+			query: hexer.Query(nil, hexer.IRI.Equal("age"), hexer.In(25, 35)),
+			bag: hexer.Bag{
+				hexer.From(B, "age", 25.5),
+				hexer.From(A, "age", 30),
+			},
+		},
+		{
+			name:  "AnyPredicate",
+			query: hexer.Query(nil, nil, hexer.Gt(26)),
+			bag: hexer.Bag{
+				hexer.From(E, "rank", xsd.Decimal(27.1)),
+				hexer.From(A, "age", 30),
+				hexer.From(C, "age", uint8(40)),
+			},
+		},
+		{
+			name:  "Subject",
+			query: hexer.Query(hexer.IRI.Equal(B), nil, hexer.Lt(100)),
+			bag:   hexer.Bag{hexer.From(B, "age", 25.5)},
+		},
+	})
+}
+
+func TestTemporal(t *testing.T) {
+	t0 := time.Date(2023, 5, 6, 0, 0, 0, 0, time.UTC)
+
+	rds := setup(hexer.Bag{
+		hexer.From(A, "created", t0),
+		hexer.From(B, "created", t0.Add(1*time.Hour)),
+		hexer.From(C, "created", t0.Add(2*time.Hour)),
+		hexer.From(D, "created", t0.Add(48*time.Hour)),
+		hexer.From(A, "ttl", time.Minute),
+	})
+
+	testMatch(t, rds, []matchCase{
+		{
+			name:  "In",
+			query: hexer.Query(nil, hexer.IRI.Equal("created"), hexer.In(t0.Add(30*time.Minute), t0.Add(2*time.Hour))),
+			bag: hexer.Bag{
+				hexer.From(B, "created", t0.Add(1*time.Hour)),
+				hexer.From(C, "created", t0.Add(2*time.Hour)),
+			},
+		},
+		{
+			name:  "Gt",
+			query: hexer.Query(nil, nil, hexer.Gt(t0.Add(24*time.Hour))),
+			bag:   hexer.Bag{hexer.From(D, "created", t0.Add(48*time.Hour))},
+		},
+		{
+			name:  "Duration",
+			query: hexer.Query(hexer.IRI.Equal(A), nil, hexer.Lt(time.Hour)),
+			bag:   hexer.Bag{hexer.From(A, "ttl", time.Minute)},
+		},
+	})
+}
+
+func TestLangString(t *testing.T) {
+	rds := setup(hexer.Bag{
+		hexer.FromLang(A, "label", "cat", "en"),
+		hexer.FromLang(A, "label", "chat", "fr"),
+		hexer.FromLang(B, "label", "dog", "en"),
+		hexer.FromLang(B, "label", "chien", "fr"),
+		hexer.From(C, "label", "cat"),
+	})
+
+	testMatch(t, rds, []matchCase{
+		{
+			name:  "HasLang",
+			query: hexer.Query(nil, hexer.IRI.Equal("label"), hexer.HasLang("en")),
+			bag: hexer.Bag{
+				hexer.FromLang(A, "label", "cat", "en"),
+				hexer.FromLang(B, "label", "dog", "en"),
+			},
+		},
+		{
+			name:  "HasLangBySubject",
+			query: hexer.Query(hexer.IRI.Equal(B), nil, hexer.HasLang("fr")),
+			bag:   hexer.Bag{hexer.FromLang(B, "label", "chien", "fr")},
+		},
+		{
+			name:  "Eq",
+			query: hexer.Query(nil, nil, hexer.Eq(xsd.Lang("cat", "en"))),
+			bag:   hexer.Bag{hexer.FromLang(A, "label", "cat", "en")},
+		},
+	})
+}
+
+func TestBinary(t *testing.T) {
+	rds := setup(hexer.Bag{
+		hexer.From(A, "hash", xsd.HexBinary{0xca, 0xfe, 0x01}),
+		hexer.From(B, "hash", xsd.HexBinary{0xca, 0xfe, 0x02}),
+		hexer.From(C, "hash", xsd.HexBinary{0xbe, 0xef}),
+		hexer.From(D, "thumbnail", []byte("png")),
+	})
+
+	testMatch(t, rds, []matchCase{
+		{
+			name:  "HasPrefix",
+			query: hexer.Query(nil, hexer.IRI.Equal("hash"), hexer.HasPrefix(xsd.HexBinary{0xca, 0xfe})),
+			bag: hexer.Bag{
+				hexer.From(A, "hash", xsd.HexBinary{0xca, 0xfe, 0x01}),
+				hexer.From(B, "hash", xsd.HexBinary{0xca, 0xfe, 0x02}),
+			},
+		},
+		{
+			name:  "Eq",
+			query: hexer.Query(nil, nil, hexer.Eq([]byte("png"))),
+			bag:   hexer.Bag{hexer.From(D, "thumbnail", []byte("png"))},
+		},
+	})
+}
+
+type Money struct {
+	Amount   int
+	Currency string
+}
+
+const XSD_MONEY = curie.IRI("ex:money")
+
+func (Money) XSDType() curie.IRI { return XSD_MONEY }
+
+// data-type which is not registered
+type Price int
+
+func (Price) XSDType() curie.IRI { return "ex:price" }
+
+func TestCustomDataType(t *testing.T) {
+	xsd.Register(XSD_MONEY,
+		func(a, b Money) int {
+			if a.Currency != b.Currency {
+				return strings.Compare(a.Currency, b.Currency)
+			}
+			return a.Amount - b.Amount
+		},
+		func(v Money) string { return fmt.Sprintf("%s %d", v.Currency, v.Amount) },
+		func(s string) (v Money, err error) {
+			_, err = fmt.Sscanf(s, "%s %d", &v.Currency, &v.Amount)
+			return
+		},
+	)
+
+	rds := setup(hexer.Bag{
+		{S: A, P: "price", O: Money{100, "EUR"}},
+		{S: B, P: "price", O: Money{250, "EUR"}},
+		{S: C, P: "price", O: Money{150, "USD"}},
+		hexer.From(D, "price", 10),
+	})
+
+	testMatch(t, rds, []matchCase{
+		{
+			name:  "In",
+			query: hexer.Query(nil, hexer.IRI.Equal("price"), hexer.Value.In(Money{0, "EUR"}, Money{200, "EUR"})),
+			bag:   hexer.Bag{{S: A, P: "price", O: Money{100, "EUR"}}},
+		},
+		{
+			name:  "Gt",
+			query: hexer.Query(nil, nil, hexer.Value.Gt(Money{200, "EUR"})),
+			bag: hexer.Bag{
+				{S: B, P: "price", O: Money{250, "EUR"}},
+				{S: C, P: "price", O: Money{150, "USD"}},
+			},
+		},
+	})
+
+	t.Run("NotRegistered", func(t *testing.T) {
+		ctx := context.Background()
+		spock := hexer.SPOCK{S: A, P: "price", O: Price(100)}
+
+		it.Then(t).Should(
+			it.Fail(func() error { return rds.Put(ctx, spock) }).Contain("not registered"),
+			it.Fail(func() error { return rds.Cut(ctx, spock) }).Contain("not registered"),
+			it.Error(rds.Match(ctx, hexer.Query(nil, nil, hexer.Value.Gt(Price(100))))).Contain("not registered"),
+			it.Equal(ephemeral.Size(rds), 4),
+		)
+	})
+}
+
+func TestCut(t *testing.T) {
+	t.Run("Cut", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		ephemeral.Cut(rds, hexer.From(C, "follows", B))
+
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(rds), 11),
+		)
+
+		testMatch(t, rds, []matchCase{
+			{
+				name:  "BySubject",
+				query: hexer.Query(hexer.IRI.Equal(C), nil, nil),
+				bag: hexer.Bag{
+					hexer.From(C, "follows", E),
+					hexer.From(C, "relates", D),
+				},
+			},
+			{
+				name:  "BySubjectObject",
+				query: hexer.Query(hexer.IRI.Equal(C), nil, hexer.Eq(B)),
+			},
+			{
+				name:  "ByPredicateObject",
+				query: hexer.Query(nil, hexer.IRI.Equal("follows"), hexer.Eq(B)),
+				bag:   hexer.Bag{hexer.From(A, "follows", B)},
+			},
+			{
+				name:  "ByObject",
+				query: hexer.Query(nil, nil, hexer.Eq(B)),
+				bag: hexer.Bag{
+					hexer.From(A, "follows", B),
+					hexer.From(D, "relates", B),
+				},
+			},
+		})
+	})
+
+	t.Run("CutNotFound", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		ephemeral.Cut(rds, hexer.From(C, "follows", G))
+		ephemeral.Cut(rds, hexer.From(N, "follows", B))
+
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(rds), 12),
+		)
+	})
+
+	t.Run("CutLast", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		ephemeral.Cut(rds, hexer.From(G, "status", "g"))
+
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(rds), 11),
+		)
+
+		testMatch(t, rds, []matchCase{
+			{
+				name:  "BySubject",
+				query: hexer.Query(hexer.IRI.Equal(G), nil, nil),
+			},
+			{
+				name:  "ByObject",
+				query: hexer.Query(nil, nil, hexer.Eq("g")),
+			},
+			{
+				name:  "ByPredicate",
+				query: hexer.Query(nil, hexer.IRI.Equal("status"), nil),
+				bag: hexer.Bag{
+					hexer.From(B, "status", "b"),
+					hexer.From(D, "status", "d"),
+				},
+			},
+		})
+	})
+
+	t.Run("CutAll", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		err := ephemeral.CutAll(rds, hexer.Query(nil, hexer.IRI.Equal("follows"), nil))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(ephemeral.Size(rds), 6),
+		)
+
+		testMatch(t, rds, []matchCase{
+			{
+				name:  "ByPredicate",
+				query: hexer.Query(nil, hexer.IRI.Equal("follows"), nil),
+			},
+			{
+				name:  "BySubject",
+				query: hexer.Query(hexer.IRI.Equal(C), nil, nil),
+				bag:   hexer.Bag{hexer.From(C, "relates", D)},
+			},
+		})
+	})
+}
+
+func TestPut(t *testing.T) {
+	Put := func(t *testing.T, rds *ephemeral.Store, spock hexer.SPOCK) bool {
+		t.Helper()
+		has, err := ephemeral.Put(rds, spock)
+		it.Then(t).Should(it.Nil(err))
+		return has
+	}
+
+	t.Run("Distinct", func(t *testing.T) {
+		rds := ephemeral.New()
+
+		it.Then(t).Should(
+			it.True(Put(t, rds, hexer.From(A, "follows", B))),
+			it.True(Put(t, rds, hexer.From(A, "follows", C))),
+			it.True(!Put(t, rds, hexer.From(A, "follows", B))),
+			it.Equal(ephemeral.Size(rds), 2),
+		)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		ephemeral.Add(rds, datasetSocialGraph())
+
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(rds), 12),
+		)
+	})
+
+	t.Run("RefreshK", func(t *testing.T) {
+		rds := ephemeral.New(ephemeral.WithRefreshK())
+
+		it.Then(t).Should(
+			it.True(Put(t, rds, hexer.From(A, "follows", B))),
+			it.True(!Put(t, rds, hexer.From(A, "follows", B))),
+			it.Equal(ephemeral.Size(rds), 1),
+		)
+	})
+
+	Head := func(t *testing.T, rds *ephemeral.Store) hexer.SPOCK {
+		t.Helper()
+		seq, err := ephemeral.Match(rds, hexer.Query(hexer.IRI.Equal(A), nil, nil))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.True(seq.Next()),
+		)
+		return seq.Head()
+	}
+
+	t.Run("K", func(t *testing.T) {
+		rds := ephemeral.New()
+		ephemeral.Put(rds, hexer.From(A, "follows", B))
+		spock := Head(t, rds)
+
+		// k-order of original statement is kept
+		ephemeral.Put(rds, hexer.From(A, "follows", B))
+
+		it.Then(t).ShouldNot(
+			it.Equal(spock.K, guid.K{}),
+		).Should(
+			it.Equal(Head(t, rds), spock),
+		)
+	})
+
+	t.Run("WithK", func(t *testing.T) {
+		k := guid.L(guid.Clock)
+		spock := hexer.SPOCK{S: A, P: "follows", O: xsd.AnyURI(B), C: 0.5, K: k}
+
+		rds := ephemeral.New()
+		ephemeral.Put(rds, spock)
+
+		store := ephemeral.New()
+		_, err := store.Add(context.Background(), hexer.Bag{spock})
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(Head(t, rds), spock),
+			it.Equal(Head(t, store), spock),
+		)
+	})
+
+	t.Run("WithRefreshK", func(t *testing.T) {
+		spock := hexer.From(A, "follows", B)
+		spock.K = guid.L(guid.Clock)
+
+		rds := ephemeral.New(ephemeral.WithRefreshK())
+		ephemeral.Put(rds, hexer.From(A, "follows", B))
+		ephemeral.Put(rds, spock)
+
+		it.Then(t).Should(
+			it.Equal(Head(t, rds), spock),
+		)
+	})
+}
+
+func TestGraph(t *testing.T) {
+	G1 := curie.IRI("g:1")
+	G2 := curie.IRI("g:2")
+
+	rds := setup(hexer.Bag{
+		hexer.Quad(G1, A, "follows", B),
+		hexer.Quad(G2, A, "follows", C),
+		hexer.From(A, "follows", D),
+	})
+
+	t.Run("Size", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(rds), 3),
+		)
+	})
+
+	testMatch(t, rds, []matchCase{
+		{
+			name:  "Default",
+			query: hexer.Query(hexer.IRI.Equal(A), nil, nil),
+			bag:   hexer.Bag{hexer.From(A, "follows", D)},
+		},
+		{
+			name:  "Named",
+			query: hexer.Query(hexer.IRI.Equal(A), nil, nil).InGraph(hexer.IRI.Equal(G1)),
+			bag:   hexer.Bag{hexer.Quad(G1, A, "follows", B)},
+		},
+		{
+			name:  "NamedAny",
+			query: hexer.Query(nil, nil, nil).InGraph(hexer.IRI.Equal(G1)),
+			bag:   hexer.Bag{hexer.Quad(G1, A, "follows", B)},
+		},
+		{
+			name:  "NamedByObject",
+			query: hexer.Query(nil, nil, hexer.Eq(C)).InGraph(hexer.IRI.Equal(G2)),
+			bag:   hexer.Bag{hexer.Quad(G2, A, "follows", C)},
+		},
+		{
+			name:  "NamedNotFound",
+			query: hexer.Query(hexer.IRI.Equal(A), nil, nil).InGraph(hexer.IRI.Equal("g:none")),
+		},
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		var err interface{ NotSupported() }
+
+		it.Then(t).Should(
+			it.Error(
+				ephemeral.Match(rds, hexer.Query(hexer.IRI.Equal(A), nil, nil).InGraph(hexer.IRI.HasPrefix("g:"))),
+			).With(&err),
+		)
+	})
+
+	t.Run("Cut", func(t *testing.T) {
+		ephemeral.Cut(rds, hexer.From(A, "follows", B))
+		ephemeral.Cut(rds, hexer.Quad(G2, A, "follows", C))
+
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(rds), 2),
+			it.Seq(match(t, rds, hexer.Query(hexer.IRI.Equal(A), nil, nil).InGraph(hexer.IRI.Equal(G1)))).Equal(
+				hexer.Quad(G1, A, "follows", B),
+			),
+			it.Seq(match(t, rds, hexer.Query(hexer.IRI.Equal(A), nil, nil).InGraph(hexer.IRI.Equal(G2)))).Equal(),
+		)
+	})
+}
+
+func TestStore(t *testing.T) {
+	var store hexer.Store = ephemeral.New()
+	ctx := context.Background()
+
+	t.Run("Add", func(t *testing.T) {
+		bag, err := store.Add(ctx, hexer.Bag{
+			hexer.From(A, "follows", B),
+			hexer.From(A, "follows", C),
+		})
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(bag).BeEmpty(),
+		)
+	})
+
+	t.Run("Put", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Nil(store.Put(ctx, hexer.From(A, "follows", D))),
+		)
+	})
+
+	t.Run("Cut", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Nil(store.Cut(ctx, hexer.From(A, "follows", C))),
+		)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		bag := hexer.Bag{}
+		seq, err := store.Match(ctx, hexer.Query(hexer.IRI.Equal(A), nil, nil))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(seq.FMap(joinK(t, &bag))),
+			it.Seq(bag).Equal(
+				hexer.From(A, "follows", B),
+				hexer.From(A, "follows", D),
+			),
+		)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		bag := hexer.Bag{hexer.From(A, "follows", C)}
+		seq, err := store.Add(ctx, bag)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("canceled"),
+			it.Seq(seq).Equal(bag...),
+			it.Error(store.Match(ctx, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Contain("canceled"),
+		)
+	})
+
+	t.Run("AddInvalid", func(t *testing.T) {
+		rds := ephemeral.New()
+		bag := hexer.Bag{
+			hexer.From(A, "follows", B),
+			{S: A, P: "price", O: Price(100)},
+			hexer.From(A, "follows", C),
+		}
+		seq, err := rds.Add(ctx, bag)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return err }).Contain("not registered"),
+			it.Seq(seq).Equal(bag[1:]...),
+			it.Equal(ephemeral.Size(rds), 1),
+		)
+	})
+}
+
+func TestJoin(t *testing.T) {
+	rds := ephemeral.New()
+	ephemeral.Add(rds, datasetSocialGraph())
+
+	Seq := func(t *testing.T, bgp ...hexer.Pattern) it.SeqOf[hexer.Binding] {
+		t.Helper()
+		bag := []hexer.Binding{}
+		seq, err := hexer.Join(context.Background(), rds, bgp...)
+		it.Then(t).Should(it.Nil(err))
+		it.Then(t).Should(
+			it.Nil(seq.FMap(func(b hexer.Binding) error { bag = append(bag, b); return nil })),
+		)
+
+		return it.Seq(bag)
+	}
+
+	t.Run("FriendsOfFriends", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t,
+				hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("y")),
+				hexer.Query(hexer.IRI.Var("y"), hexer.IRI.Equal("follows"), hexer.Var("z")),
+				hexer.Query(hexer.IRI.Var("z"), hexer.IRI.Equal("status"), hexer.Var("status")),
+			).Equal(
+				hexer.Binding{"x": xsd.AnyURI(B), "y": xsd.AnyURI(F), "z": xsd.AnyURI(G), "status": xsd.String("g")},
+				hexer.Binding{"x": xsd.AnyURI(E), "y": xsd.AnyURI(F), "z": xsd.AnyURI(G), "status": xsd.String("g")},
+			),
+		)
+	})
+
+	t.Run("Selective", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t,
+				hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("y")),
+				hexer.Query(hexer.IRI.Var("y"), hexer.IRI.Equal("status"), hexer.Eq("b")),
+			).Equal(
+				hexer.Binding{"x": xsd.AnyURI(C), "y": xsd.AnyURI(B)},
+				hexer.Binding{"x": xsd.AnyURI(A), "y": xsd.AnyURI(B)},
+			),
+		)
+	})
+
+	t.Run("Literal", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t,
+				hexer.Query(hexer.IRI.Equal(D), hexer.IRI.Equal("status"), hexer.Var("x")),
+				hexer.Query(hexer.IRI.Var("x"), nil, nil),
+			).Equal(),
+		)
+	})
+}
+
+// stream fails after n statements, e.g. throttling of remote storage
+type faulty struct {
+	hexer.Stream
+	n int
+}
+
+func (s *faulty) Next() bool {
+	if s.n == 0 {
+		return false
+	}
+	s.n--
+	return s.Stream.Next()
+}
+
+func (s *faulty) Err() error {
+	if s.n == 0 {
+		return fmt.Errorf("throttled")
+	}
+	return nil
+}
+
+func (s *faulty) FMap(f func(hexer.SPOCK) error) error {
+	for s.Next() {
+		if err := f(s.Head()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+type faultyGetter struct{ hexer.Getter }
+
+func (g faultyGetter) Match(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
+	seq, err := g.Getter.Match(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return &faulty{Stream: seq, n: 1}, nil
+}
+
+func TestStream(t *testing.T) {
+	rds := ephemeral.New()
+	ephemeral.Add(rds, datasetSocialGraph())
+
+	t.Run("Close", func(t *testing.T) {
+		seq, err := ephemeral.Match(rds, hexer.Query(nil, nil, nil))
+		it.Then(t).Should(it.Nil(err))
+
+		it.Then(t).Should(
+			it.Equal(seq.Next(), true),
+			it.Nil(seq.Close()),
+			it.Equal(seq.Next(), false),
+			it.Nil(seq.Err()),
+		)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		seq, err := ephemeral.Match(rds, hexer.Query(nil, nil, nil))
+		it.Then(t).Should(it.Nil(err))
+
+		bag := hexer.Bag{}
+		seq = hexer.NewFilter(func(hexer.SPOCK) bool { return true }, &faulty{Stream: seq, n: 2})
+
+		it.Then(t).Should(
+			it.Fail(func() error { return seq.FMap(bag.Join) }).Contain("throttled"),
+			it.Equal(len(bag), 2),
+			it.Fail(seq.Err).Contain("throttled"),
+		)
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		seq, err := hexer.Join(context.Background(), faultyGetter{rds},
+			hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("y")),
+			hexer.Query(hexer.IRI.Var("y"), hexer.IRI.Equal("follows"), hexer.Var("z")),
+		)
+		it.Then(t).Should(it.Nil(err))
+
+		it.Then(t).Should(
+			it.Fail(func() error {
+				return seq.FMap(func(hexer.Binding) error { return nil })
+			}).Contain("throttled"),
+			it.Fail(seq.Err).Contain("throttled"),
+		)
+	})
+}
+
+func TestBinding(t *testing.T) {
+	rds := ephemeral.New()
+	ephemeral.Add(rds, datasetSocialGraph())
+
+	Seq := func(t *testing.T, f func(hexer.Stream) hexer.Bindings, q hexer.Pattern) it.SeqOf[hexer.Binding] {
+		t.Helper()
+		bag := []hexer.Binding{}
+		seq, err := ephemeral.Match(rds, q)
+		it.Then(t).Should(it.Nil(err))
+		it.Then(t).Should(
+			it.Nil(f(seq).FMap(func(b hexer.Binding) error { bag = append(bag, b); return nil })),
+		)
+
+		return it.Seq(bag)
+	}
+
+	t.Run("Solve", func(t *testing.T) {
+		q := hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("status"), hexer.Var("status"))
+
+		it.Then(t).Should(
+			it.Seq(q.Vars()).Equal("x", "status"),
+			Seq(t, func(s hexer.Stream) hexer.Bindings { return hexer.Solve(q, s) }, q).Equal(
+				hexer.Binding{"x": xsd.AnyURI(G), "status": xsd.String("g")},
+				hexer.Binding{"x": xsd.AnyURI(B), "status": xsd.String("b")},
+				hexer.Binding{"x": xsd.AnyURI(D), "status": xsd.String("d")},
+			),
+		)
+	})
+
+	t.Run("Project", func(t *testing.T) {
+		q := hexer.Query(hexer.IRI.Equal(C), hexer.IRI.Var("p"), hexer.Var("o"))
+
+		it.Then(t).Should(
+			Seq(t, func(s hexer.Stream) hexer.Bindings { return hexer.Project(hexer.Solve(q, s), "o") }, q).Equal(
+				hexer.Binding{"o": xsd.AnyURI(B)},
+				hexer.Binding{"o": xsd.AnyURI(E)},
+				hexer.Binding{"o": xsd.AnyURI(D)},
+			),
+		)
+	})
+
+	t.Run("Bind", func(t *testing.T) {
+		q := hexer.Query(hexer.IRI.Var("x"), hexer.IRI.Equal("follows"), hexer.Var("y"))
+		b, ok := q.Bind(hexer.Binding{"x": xsd.AnyURI(A)})
+
+		it.Then(t).Should(
+			it.True(ok),
+			it.Equal(b.Dump(), hexer.Query(hexer.IRI.Equal(A), hexer.IRI.Equal("follows"), nil).Dump()),
+			it.Equal(hexer.Binding{"x": xsd.AnyURI(A), "y": xsd.String("y")}.String(), `{?x: [u:A], ?y: "y"}`),
+		)
+
+		_, ok = q.Bind(hexer.Binding{"x": xsd.String("x")})
+		it.Then(t).ShouldNot(it.True(ok))
+	})
+}
+
+func TestSPARQL(t *testing.T) {
+	rds := ephemeral.New()
+	ephemeral.Add(rds, datasetSocialGraph())
+	ephemeral.Add(rds, hexer.Bag{
+		hexer.From(A, "age", 30),
+		hexer.From(B, "age", 17),
+		hexer.From(C, "age", 45.5),
+	})
+
+	Seq := func(t *testing.T, query string) it.SeqOf[hexer.Binding] {
+		t.Helper()
+		bag := []hexer.Binding{}
+		seq, err := sparql.Select(context.Background(), rds, query)
+		it.Then(t).Should(it.Nil(err))
+		it.Then(t).Should(
+			it.Nil(seq.FMap(func(b hexer.Binding) error { bag = append(bag, b); return nil })),
+		)
+
+		return it.Seq(bag)
+	}
+
+	t.Run("Join", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t, `
+				SELECT ?x ?status
+				WHERE { ?x <follows> ?y . ?y <status> ?status }
+				ORDER BY DESC(?status)
+			`).Equal(
+				hexer.Binding{"x": xsd.AnyURI(F), "status": xsd.String("g")},
+				hexer.Binding{"x": xsd.AnyURI(C), "status": xsd.String("b")},
+				hexer.Binding{"x": xsd.AnyURI(A), "status": xsd.String("b")},
+			),
+		)
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t, `
+				SELECT ?x
+				WHERE { ?x <follows> ?y FILTER strStarts(str(?x), "u:") }
+				LIMIT 2
+			`).Equal(
+				hexer.Binding{"x": xsd.AnyURI(A)},
+				hexer.Binding{"x": xsd.AnyURI(B)},
+			),
+		)
+	})
+
+	t.Run("Numbers", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t, `
+				SELECT ?x ?age
+				WHERE { ?x <age> ?age FILTER (?age >= 18) }
+				ORDER BY ?age
+			`).Equal(
+				hexer.Binding{"x": xsd.AnyURI(A), "age": xsd.Integer(30)},
+				hexer.Binding{"x": xsd.AnyURI(C), "age": xsd.Double(45.5)},
+			),
+			Seq(t, `
+				SELECT ?x WHERE { ?x <age> ?age FILTER (?age = 30.0) }
+			`).Equal(
+				hexer.Binding{"x": xsd.AnyURI(A)},
+			),
+			Seq(t, `
+				SELECT ?x WHERE { ?x <age> ?age FILTER (?age < 45.5 && ?age > 17) }
+			`).Equal(
+				hexer.Binding{"x": xsd.AnyURI(A)},
+			),
+		)
+	})
+
+	t.Run("PrefixLangString", func(t *testing.T) {
+		rds := setup(hexer.Bag{
+			hexer.From(A, "name", "xyz"),
+			hexer.FromLang(B, "name", "xyz", "en"),
+			hexer.From(C, "name", "abc"),
+		})
+
+		for _, query := range []string{
+			`SELECT ?s WHERE { ?s <name> ?o FILTER strStarts(?o, "x") }`,
+			`SELECT ?s WHERE { ?s <name> ?o FILTER strStarts(str(?o), "x") }`,
+			`SELECT ?s WHERE { ?s <name> ?o FILTER regex(?o, "^x") }`,
+		} {
+			bag := []hexer.Binding{}
+			seq, err := sparql.Select(context.Background(), rds, query)
+			it.Then(t).Should(it.Nil(err))
+			it.Then(t).Should(
+				it.Nil(seq.FMap(func(b hexer.Binding) error { bag = append(bag, b); return nil })),
+				it.Seq(bag).Equal(
+					hexer.Binding{"s": xsd.AnyURI(A)},
+					hexer.Binding{"s": xsd.AnyURI(B)},
+				),
+			)
+		}
+	})
+
+	t.Run("Distinct", func(t *testing.T) {
+		it.Then(t).Should(
+			Seq(t, `
+				SELECT DISTINCT ?y WHERE { ?x <follows> ?y } OFFSET 1
+			`).Equal(
+				hexer.Binding{"y": xsd.AnyURI(E)},
+				hexer.Binding{"y": xsd.AnyURI(G)},
+				hexer.Binding{"y": xsd.AnyURI(F)},
+			),
+		)
+	})
+}
+
+// the test is designed for race detector: go test -race ./...
+func TestConcurrency(t *testing.T) {
+	const n = 200
+
+	node := func(i int) curie.IRI { return curie.IRI(fmt.Sprintf("u:%03d", i)) }
+
+	t.Run("Consistent", func(t *testing.T) {
+		rds := ephemeral.New()
+		failed := make(chan hexer.SPOCK, n)
+
+		wg := sync.WaitGroup{}
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < n; i += 4 {
+					ephemeral.Put(rds, hexer.From(node(i), "follows", node(i+1)))
+				}
+			}(w)
+		}
+
+		// statement observed via pos index is visible through spo index
+		scan := hexer.Query(nil, hexer.IRI.Equal("follows"), hexer.HasPrefix(curie.IRI("u:")))
+		it.Then(t).Should(it.Equal(scan.Strategy, hexer.STRATEGY_POS))
+
+		for r := 0; r < 4; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					seq, _ := ephemeral.Match(rds, scan)
+					seq.FMap(func(spock hexer.SPOCK) error {
+						bag := hexer.Bag{}
+						q := hexer.Query(hexer.IRI.Equal(spock.S), hexer.IRI.Equal(spock.P), hexer.Value.Eq(spock.O))
+						seq, _ := ephemeral.Match(rds, q)
+						if seq.FMap(bag.Join); len(bag) != 1 {
+							failed <- spock
+						}
+						return nil
+					})
+				}
+			}()
+		}
+
+		wg.Wait()
+		close(failed)
+
+		bag := hexer.Bag{}
+		for spock := range failed {
+			bag = append(bag, spock)
+		}
+
+		it.Then(t).Should(
+			it.Seq(bag).BeEmpty(),
+			it.Equal(ephemeral.Size(rds), n),
+		)
+	})
+
+	t.Run("ReadersAndWriters", func(t *testing.T) {
+		var store hexer.Store = ephemeral.New()
+		ctx := context.Background()
+
+		wg := sync.WaitGroup{}
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < n; i += 4 {
+					store.Put(ctx, hexer.From(node(i), "follows", node(i+1)))
+					store.Put(ctx, hexer.From(node(i), "status", fmt.Sprintf("s%d", i)))
+					if i%3 == 0 {
+						store.Cut(ctx, hexer.From(node(i), "follows", node(i+1)))
+					}
+				}
+			}(w)
+		}
+
+		for r := 0; r < 4; r++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 10; i++ {
+					seq, _ := sparql.Select(ctx, store, `
+						SELECT ?x ?s WHERE { ?x <follows> ?y . ?y <status> ?s }
+					`)
+					seq.FMap(func(hexer.Binding) error { return nil })
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		bag := hexer.Bag{}
+		seq, err := store.Match(ctx, hexer.Query(nil, hexer.IRI.Equal("follows"), nil))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(seq.FMap(bag.Join)),
+			it.Equal(len(bag), n-(n+2)/3),
+		)
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Snapshot", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		view := ephemeral.Snapshot(rds)
+
+		ephemeral.Put(rds, hexer.From(A, "follows", C))
+		ephemeral.Put(rds, hexer.From(N, "follows", A))
+		ephemeral.Cut(rds, hexer.From(A, "follows", B))
+		ephemeral.Cut(rds, hexer.From(D, "status", "d"))
+
+		it.Then(t).Should(
+			it.Equal(view.Size(), 12),
+			it.Equal(ephemeral.Size(rds), 12),
+			it.Seq(match(t, view, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Equal(
+				hexer.From(A, "follows", B),
+			),
+			it.Seq(match(t, rds, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Equal(
+				hexer.From(A, "follows", C),
+			),
+			it.Seq(match(t, view, hexer.Query(nil, nil, hexer.Eq(A)))).Equal(),
+			it.Seq(match(t, view, hexer.Query(nil, hexer.IRI.Equal("status"), nil))).Equal(
+				hexer.From(G, "status", "g"),
+				hexer.From(B, "status", "b"),
+				hexer.From(D, "status", "d"),
+			),
+			it.Seq(match(t, rds, hexer.Query(nil, hexer.IRI.Equal("status"), nil))).Equal(
+				hexer.From(G, "status", "g"),
+				hexer.From(B, "status", "b"),
+			),
+		)
+	})
+
+	t.Run("Fork", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		fork := ephemeral.Fork(rds)
+
+		ephemeral.Put(rds, hexer.From(A, "follows", C))
+		ephemeral.Put(fork, hexer.From(A, "follows", D))
+		ephemeral.Cut(fork, hexer.From(A, "follows", B))
+
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(rds), 13),
+			it.Equal(ephemeral.Size(fork), 12),
+			it.Seq(match(t, rds, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Equal(
+				hexer.From(A, "follows", C),
+				hexer.From(A, "follows", B),
+			),
+			it.Seq(match(t, fork, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Equal(
+				hexer.From(A, "follows", D),
+			),
+			it.Seq(match(t, rds, hexer.Query(nil, hexer.IRI.Equal("follows"), hexer.Eq(B)))).Equal(
+				hexer.From(C, "follows", B),
+				hexer.From(A, "follows", B),
+			),
+			it.Seq(match(t, fork, hexer.Query(nil, hexer.IRI.Equal("follows"), hexer.Eq(B)))).Equal(
+				hexer.From(C, "follows", B),
+			),
+		)
+	})
+
+	t.Run("ForkOfView", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		view := ephemeral.Snapshot(rds)
+		fork := view.Fork()
+
+		ephemeral.Put(fork, hexer.From(A, "follows", D))
+
+		it.Then(t).Should(
+			it.Equal(view.Size(), 12),
+			it.Equal(ephemeral.Size(fork), 13),
+			it.Seq(match(t, view, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Equal(
+				hexer.From(A, "follows", B),
+			),
+			it.Seq(match(t, rds, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Equal(
+				hexer.From(A, "follows", B),
+			),
+		)
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		view := ephemeral.Snapshot(rds)
+		fork := view.Fork()
+		view.Release()
+		view.Release()
+
+		ephemeral.Put(rds, hexer.From(A, "follows", C))
+		ephemeral.Cut(rds, hexer.From(A, "follows", B))
+
+		next := ephemeral.Snapshot(rds)
+		ephemeral.Put(rds, hexer.From(A, "follows", D))
+		next.Release()
+		ephemeral.Cut(rds, hexer.From(A, "follows", C))
+
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(rds), 12),
+			it.Seq(match(t, rds, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Equal(
+				hexer.From(A, "follows", D),
+			),
+			it.Seq(match(t, fork, hexer.Query(hexer.IRI.Equal(A), nil, nil))).Equal(
+				hexer.From(A, "follows", B),
+			),
+			it.Seq(match(t, fork, hexer.Query(nil, hexer.IRI.Equal("follows"), hexer.Eq(B)))).Equal(
+				hexer.From(C, "follows", B),
+				hexer.From(A, "follows", B),
+			),
+		)
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		rds := setup(datasetSocialGraph())
+		view := ephemeral.Snapshot(rds)
+
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				ephemeral.Put(rds, hexer.From(curie.IRI(fmt.Sprintf("u:%03d", i)), "follows", B))
+				ephemeral.Cut(rds, hexer.From(C, "follows", B))
+			}
+		}()
+
+		bags := make([]hexer.Bag, 10)
+		go func() {
+			defer wg.Done()
+			for i := range bags {
+				seq, _ := view.Match(ctx, hexer.Query(nil, hexer.IRI.Equal("follows"), hexer.Eq(B)))
+				seq.FMap(joinK(t, &bags[i]))
+			}
+		}()
+		wg.Wait()
+
+		for _, bag := range bags {
+			it.Then(t).Should(
+				it.Seq(bag).Equal(
+					hexer.From(C, "follows", B),
+					hexer.From(A, "follows", B),
+				),
+			)
+		}
+	})
+}
+
+func TestSaveLoad(t *testing.T) {
+	G1 := curie.IRI("g:1")
+	T := time.Date(2023, 3, 7, 12, 30, 0, 0, time.UTC)
+
+	bag := append(datasetSocialGraph(),
+		hexer.Quad(G1, A, "follows", C),
+		hexer.FromLang(A, "name", "Alice", "en"),
+		hexer.FromLang(A, "name", "Alice@home", "fi"),
+		hexer.From(A, "age", 30),
+		hexer.From(A, "score", 0.75),
+		hexer.From(A, "born", T),
+		hexer.From(A, "active", true),
+		hexer.From(A, "likes", B),
+		hexer.From(A, "blob", []byte{0xde, 0xad}),
+		hexer.SPOCK{S: A, P: "trust", O: xsd.AnyURI(C), C: 0.5},
+	)
+
+	rds := setup(bag)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		it.Then(t).Should(it.Nil(ephemeral.Save(rds, buf)))
+
+		file := buf.Bytes()
+		store, err := ephemeral.Load(bytes.NewReader(file))
+		it.Then(t).Should(it.Nil(err))
+
+		// k-order and credibility are persisted
+		again := &bytes.Buffer{}
+		it.Then(t).Should(
+			it.Nil(ephemeral.Save(store, again)),
+			it.Equal(again.String(), string(file)),
+		)
+
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(store), len(bag)),
+			it.Seq(match(t, store, hexer.Query(nil, nil, nil))).Equal(
+				match(t, rds, hexer.Query(nil, nil, nil))...,
+			),
+			it.Seq(match(t, store, hexer.Query(nil, nil, hexer.HasLang("en")))).Equal(
+				hexer.FromLang(A, "name", "Alice", "en"),
+			),
+			it.Seq(match(t, store, hexer.Query(nil, nil, nil).InGraph(hexer.IRI.Equal(G1)))).Equal(
+				hexer.Quad(G1, A, "follows", C),
+			),
+			it.Seq(match(t, store, hexer.Query(nil, hexer.IRI.Equal("follows"), hexer.Eq(B)))).Equal(
+				hexer.From(C, "follows", B),
+				hexer.From(A, "follows", B),
+			),
+		)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		it.Then(t).Should(it.Nil(ephemeral.Save(ephemeral.New(), buf)))
+
+		store, err := ephemeral.Load(buf)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(ephemeral.Size(store), 0),
+		)
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		it.Then(t).Should(it.Nil(ephemeral.Save(rds, buf)))
+		file := buf.Bytes()
+
+		version := append([]byte("HEXR"), 0x7f)
+
+		it.Then(t).Should(
+			it.Fail(func() error { _, err := ephemeral.Load(strings.NewReader("")); return err }).Contain("invalid file format"),
+			it.Fail(func() error { _, err := ephemeral.Load(strings.NewReader("JSON{}")); return err }).Contain("invalid file format"),
+			it.Fail(func() error { _, err := ephemeral.Load(bytes.NewReader(version)); return err }).Contain("unsupported file version"),
+			it.Fail(func() error { _, err := ephemeral.Load(bytes.NewReader(file[:len(file)-3])); return err }).Contain("invalid file format"),
+		)
+	})
+}
+
+func TestDurable(t *testing.T) {
+	bag := append(datasetSocialGraph(),
+		hexer.FromLang(A, "name", "Alice", "en"),
+		hexer.From(A, "age", 30),
+		hexer.SPOCK{S: A, P: "trust", O: xsd.AnyURI(C), C: 0.5},
+	)
+
+	Scan := func(t *testing.T, store *ephemeral.Store) hexer.Bag {
+		t.Helper()
+		bag := hexer.Bag{}
+		seq, err := ephemeral.Match(store, hexer.Query(nil, nil, nil))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(seq.FMap(bag.Join)),
+		)
+		return bag
+	}
+
+	Segments := func(t *testing.T, dir string) []string {
+		t.Helper()
+		seq, err := filepath.Glob(filepath.Join(dir, "*.wal"))
+		it.Then(t).Should(it.Nil(err))
+		return seq
+	}
+
+	Open := func(t *testing.T, dir string) *ephemeral.Store {
+		t.Helper()
+		store, err := ephemeral.Open(dir)
+		it.Then(t).Should(it.Nil(err))
+		return store
+	}
+
+	t.Run("Replay", func(t *testing.T) {
+		dir := t.TempDir()
+		store := Open(t, dir)
+		_, err := store.Add(context.Background(), bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(store.Cut(context.Background(), hexer.From(A, "follows", B))),
+			it.Nil(ephemeral.Close(store)),
+		)
+
+		again := Open(t, dir)
+		defer ephemeral.Close(again)
+
+		// k-order and credibility are persisted
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(again), len(bag)-1),
+			it.Seq(Scan(t, again)).Equal(Scan(t, store)...),
+		)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		store := Open(t, t.TempDir())
+		it.Then(t).Should(
+			it.Nil(ephemeral.Close(store)),
+			it.Nil(ephemeral.Close(store)),
+			it.Fail(func() error { return store.Put(context.Background(), hexer.From(A, "name", "Alice")) }).Contain("closed"),
+			it.Error(ephemeral.Put(store, hexer.From(A, "name", "Alice"))).Contain("closed"),
+			it.Fail(func() error { return ephemeral.Add(store, bag) }).Contain("closed"),
+			it.Fail(func() error { return ephemeral.Cut(store, hexer.From(A, "name", "Alice")) }).Contain("closed"),
+			it.Equal(ephemeral.Size(store), 0),
+			it.Nil(ephemeral.Close(ephemeral.New())),
+		)
+	})
+
+	t.Run("TornTail", func(t *testing.T) {
+		dir := t.TempDir()
+		store := Open(t, dir)
+		_, err := store.Add(context.Background(), bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(ephemeral.Close(store)),
+		)
+
+		seq := Segments(t, dir)
+		file, err := os.ReadFile(seq[0])
+		it.Then(t).Should(it.Nil(err))
+		it.Then(t).Should(it.Nil(os.WriteFile(seq[0], file[:len(file)-3], 0644)))
+
+		// the last statement is lost, the log is writable
+		again := Open(t, dir)
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(again), len(bag)-1),
+			it.Nil(again.Put(context.Background(), hexer.From(B, "name", "Bob"))),
+			it.Nil(ephemeral.Close(again)),
+		)
+
+		again = Open(t, dir)
+		defer ephemeral.Close(again)
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(again), len(bag)),
+		)
+	})
+
+	t.Run("Checksum", func(t *testing.T) {
+		dir := t.TempDir()
+		alice := hexer.From(A, "name", "Alice")
+		alice.K = guid.L(guid.Clock)
+
+		store := Open(t, dir)
+		it.Then(t).Should(
+			it.Nil(store.Put(context.Background(), alice)),
+			it.Nil(store.Put(context.Background(), hexer.From(B, "name", "Bob"))),
+			it.Nil(ephemeral.Close(store)),
+		)
+
+		seq := Segments(t, dir)
+		file, err := os.ReadFile(seq[0])
+		it.Then(t).Should(it.Nil(err))
+		at := bytes.LastIndex(file, []byte("Bob"))
+		file[at] = 'R'
+		it.Then(t).Should(it.Nil(os.WriteFile(seq[0], file, 0644)))
+
+		again := Open(t, dir)
+		defer ephemeral.Close(again)
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(again), 1),
+			it.Seq(Scan(t, again)).Equal(alice),
+		)
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		dir := t.TempDir()
+		store := Open(t, dir)
+		_, err := store.Add(context.Background(), bag)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(ephemeral.Compact(store)),
+			it.Nil(store.Cut(context.Background(), hexer.From(A, "follows", B))),
+			it.Nil(ephemeral.Close(store)),
+		)
+
+		// the log contains writes made after compaction
+		it.Then(t).Should(
+			it.Equal(len(Segments(t, dir)), 1),
+		)
+
+		again := Open(t, dir)
+		defer ephemeral.Close(again)
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(again), len(bag)-1),
+			it.Seq(Scan(t, again)).Equal(Scan(t, store)...),
+			it.Nil(ephemeral.Compact(again)),
+			it.Equal(len(Segments(t, dir)), 1),
+		)
+
+		it.Then(t).Should(
+			it.Fail(func() error { return ephemeral.Compact(ephemeral.New()) }).Contain("not durable"),
+		)
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		dir := t.TempDir()
+		store := Open(t, dir)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					store.Put(context.Background(), hexer.From(curie.New("u:%d", i), "seq", j))
+				}
+			}(i)
+		}
+
+		for i := 0; i < 3; i++ {
+			it.Then(t).Should(it.Nil(ephemeral.Compact(store)))
+		}
+		wg.Wait()
+		it.Then(t).Should(it.Nil(ephemeral.Close(store)))
+
+		again := Open(t, dir)
+		defer ephemeral.Close(again)
+		it.Then(t).Should(
+			it.Equal(ephemeral.Size(again), 200),
+		)
+	})
+}
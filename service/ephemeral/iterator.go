@@ -1,69 +1,84 @@
 package ephemeral
 
 import (
+	"sync"
+
 	"github.com/fogfish/hexer"
 	"github.com/fogfish/skiplist"
 )
 
 // evaluates query patterns against lists
 type seqBuilder[A, B, C any] interface {
-	L1(*skiplist.SkipList[A, *skiplist.SkipList[B, *skiplist.SkipList[C, k]]]) Seq[A, *skiplist.SkipList[B, *skiplist.SkipList[C, k]]]
-	L2(*skiplist.SkipList[B, *skiplist.SkipList[C, k]]) Seq[B, *skiplist.SkipList[C, k]]
-	L3(*skiplist.SkipList[C, k]) Seq[C, k]
-	ToSPOCK(A, B, C) hexer.SPOCK
+	L1(*skiplist.SkipList[A, list[B, list[C, ck]]]) Seq[A, list[B, list[C, ck]]]
+	L2(*skiplist.SkipList[B, list[C, ck]]) Seq[B, list[C, ck]]
+	L3(*skiplist.SkipList[C, ck]) Seq[C, ck]
+	ToSPOCK(A, B, C, ck) hexer.SPOCK
 }
 
+// iterator over the index, the read lock of the store is acquired for each
+// step so that writers are not blocked while the stream is consumed.
 type iterator[A, B, C any] struct {
-	a   A
-	b   B
-	c   C
-	abc Seq[A, *skiplist.SkipList[B, *skiplist.SkipList[C, k]]]
-	_bc Seq[B, *skiplist.SkipList[C, k]]
-	__c Seq[C, k]
-	hlp seqBuilder[A, B, C]
+	lock sync.Locker
+	a    A
+	b    B
+	c    C
+	ck   ck
+	abc  Seq[A, list[B, list[C, ck]]]
+	_bc  Seq[B, list[C, ck]]
+	__c  Seq[C, ck]
+	hlp  seqBuilder[A, B, C]
 }
 
 func newIterator[A, B, C any](
+	lock sync.Locker,
 	hlp seqBuilder[A, B, C],
-	seq *skiplist.SkipList[A, *skiplist.SkipList[B, *skiplist.SkipList[C, k]]],
+	seq list[A, list[B, list[C, ck]]],
 ) *iterator[A, B, C] {
 	return &iterator[A, B, C]{
-		hlp: hlp,
-		abc: hlp.L1(seq),
+		lock: lock,
+		hlp:  hlp,
+		abc:  hlp.L1(seq.SkipList),
 	}
 }
 
 func (iter *iterator[A, B, C]) Head() hexer.SPOCK {
-	return iter.hlp.ToSPOCK(iter.a, iter.b, iter.c)
+	return iter.hlp.ToSPOCK(iter.a, iter.b, iter.c, iter.ck)
 }
 
 func (iter *iterator[A, B, C]) Next() bool {
+	iter.lock.Lock()
+	defer iter.lock.Unlock()
+
+	return iter.next()
+}
+
+func (iter *iterator[A, B, C]) next() bool {
 	if iter._bc == nil {
 		if iter.abc == nil || !iter.abc.Next() {
 			return false
 		}
 		a, _bc := iter.abc.Head()
 		iter.a = a
-		iter._bc = iter.hlp.L2(_bc)
+		iter._bc = iter.hlp.L2(_bc.SkipList)
 	}
 
 	if iter.__c == nil {
 		if iter._bc == nil || !iter._bc.Next() {
 			iter._bc = nil
-			return iter.Next()
+			return iter.next()
 		}
 
 		b, __c := iter._bc.Head()
 		iter.b = b
-		iter.__c = iter.hlp.L3(__c)
+		iter.__c = iter.hlp.L3(__c.SkipList)
 	}
 
 	if iter.__c == nil || !iter.__c.Next() {
 		iter.__c = nil
-		return iter.Next()
+		return iter.next()
 	}
 
-	iter.c, _ = iter.__c.Head()
+	iter.c, iter.ck = iter.__c.Head()
 
 	return true
 }
@@ -76,3 +91,10 @@ func (iter *iterator[A, B, C]) FMap(f func(hexer.SPOCK) error) error {
 	}
 	return nil
 }
+
+func (iter *iterator[A, B, C]) Err() error { return nil }
+
+func (iter *iterator[A, B, C]) Close() error {
+	iter.abc, iter._bc, iter.__c = nil, nil, nil
+	return nil
+}
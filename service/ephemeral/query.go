@@ -24,7 +24,7 @@ func queryIRI[A, B any](
 	var seq *skiplist.Iterator[curie.IRI, B]
 
 	switch {
-	case pred == nil:
+	case pred == nil || pred.Clause == hexer.VAR:
 		seq = skiplist.Values(list)
 	case pred.Clause == hexer.EQ:
 		seq = skiplist.Slice(list, pred.Value, 1)
@@ -62,7 +62,7 @@ func queryXSD[A, B any](
 	var seq *skiplist.Iterator[xsd.Value, B]
 
 	switch {
-	case pred == nil:
+	case pred == nil || pred.Clause == hexer.VAR:
 		seq = skiplist.Values(list)
 	case pred.Clause == hexer.EQ:
 		seq = skiplist.Slice(list, pred.Value, 1)
@@ -82,13 +82,13 @@ func queryXSD[A, B any](
 		if before == nil {
 			return nil
 		}
-		return NewDropWhileType[B](pred.Value.XSDType(), before).(Seq[A, B])
+		return NewDropWhileKind[B](pred.Value, before).(Seq[A, B])
 	case pred.Clause == hexer.GT:
 		_, after := skiplist.Split(list, pred.Value)
 		if after == nil {
 			return nil
 		}
-		return NewTakeWhileType[B](pred.Value.XSDType(), after).(Seq[A, B])
+		return NewTakeWhileKind[B](pred.Value, after).(Seq[A, B])
 	}
 
 	if seq == nil {
@@ -119,44 +119,45 @@ func (seq *takeWhile[A, B]) Next() bool {
 	return true
 }
 
-// take sequence elements while xsd.Value belongs to same category (type)
-type takeWhileType[T any] struct {
+// take sequence elements while xsd.Value belongs to same kind (e.g. numbers)
+type takeWhileKind[T any] struct {
 	Seq[xsd.Value, T]
-	cat curie.IRI
+	kind xsd.Value
 }
 
-func NewTakeWhileType[T any](cat curie.IRI, seq Seq[xsd.Value, T]) Seq[xsd.Value, T] {
-	return &takeWhileType[T]{Seq: seq, cat: cat}
+func NewTakeWhileKind[T any](kind xsd.Value, seq Seq[xsd.Value, T]) Seq[xsd.Value, T] {
+	return &takeWhileKind[T]{Seq: seq, kind: kind}
 }
 
-func (seq *takeWhileType[T]) Next() bool {
+func (seq *takeWhileKind[T]) Next() bool {
 	if !seq.Seq.Next() {
 		return false
 	}
 
-	if key, _ := seq.Seq.Head(); key.XSDType() != seq.cat {
+	if key, _ := seq.Seq.Head(); !xsd.SameKind(key, seq.kind) {
 		return false
 	}
 
 	return true
 }
 
-type dropWhileType[T any] struct {
+// drop sequence elements while xsd.Value do not belong to same kind
+type dropWhileKind[T any] struct {
 	Seq[xsd.Value, T]
-	cat curie.IRI
+	kind xsd.Value
 }
 
-func NewDropWhileType[T any](cat curie.IRI, seq Seq[xsd.Value, T]) Seq[xsd.Value, T] {
-	return &dropWhileType[T]{Seq: seq, cat: cat}
+func NewDropWhileKind[T any](kind xsd.Value, seq Seq[xsd.Value, T]) Seq[xsd.Value, T] {
+	return &dropWhileKind[T]{Seq: seq, kind: kind}
 }
 
-func (seq *dropWhileType[T]) Next() bool {
+func (seq *dropWhileKind[T]) Next() bool {
 	for {
 		if !seq.Seq.Next() {
 			return false
 		}
 
-		if key, _ := seq.Seq.Head(); key.XSDType() == seq.cat {
+		if key, _ := seq.Seq.Head(); xsd.SameKind(key, seq.kind) {
 			return true
 		}
 	}
@@ -173,12 +174,12 @@ func (q querySPO) L2(list *skiplist.SkipList[p, __o]) Seq[p, __o] {
 	return queryIRI[p](q.P, list)
 }
 
-func (q querySPO) L3(list *skiplist.SkipList[o, k]) Seq[o, k] {
+func (q querySPO) L3(list *skiplist.SkipList[o, ck]) Seq[o, ck] {
 	return queryXSD[o](q.O, list)
 }
 
-func (q querySPO) ToSPOCK(s s, p p, o o) hexer.SPOCK {
-	return hexer.SPOCK{S: s, P: p, O: o}
+func (q querySPO) ToSPOCK(s s, p p, o o, ck ck) hexer.SPOCK {
+	return hexer.SPOCK{G: graphOf(hexer.Pattern(q)), S: s, P: p, O: o, C: ck.C, K: ck.K}
 }
 
 // executes query against ⟨s, o, p⟩ data structure
@@ -192,12 +193,12 @@ func (q querySOP) L2(list *skiplist.SkipList[o, __p]) Seq[o, __p] {
 	return queryXSD[o](q.O, list)
 }
 
-func (q querySOP) L3(list *skiplist.SkipList[p, k]) Seq[p, k] {
+func (q querySOP) L3(list *skiplist.SkipList[p, ck]) Seq[p, ck] {
 	return queryIRI[p](q.P, list)
 }
 
-func (q querySOP) ToSPOCK(s s, o o, p p) hexer.SPOCK {
-	return hexer.SPOCK{S: s, P: p, O: o}
+func (q querySOP) ToSPOCK(s s, o o, p p, ck ck) hexer.SPOCK {
+	return hexer.SPOCK{G: graphOf(hexer.Pattern(q)), S: s, P: p, O: o, C: ck.C, K: ck.K}
 }
 
 // executes query against ⟨p, s, o⟩ data structure
@@ -211,12 +212,12 @@ func (q queryPSO) L2(list *skiplist.SkipList[s, __o]) Seq[s, __o] {
 	return queryIRI[s](q.S, list)
 }
 
-func (q queryPSO) L3(list *skiplist.SkipList[o, k]) Seq[o, k] {
+func (q queryPSO) L3(list *skiplist.SkipList[o, ck]) Seq[o, ck] {
 	return queryXSD[o](q.O, list)
 }
 
-func (q queryPSO) ToSPOCK(p p, s s, o o) hexer.SPOCK {
-	return hexer.SPOCK{S: s, P: p, O: o}
+func (q queryPSO) ToSPOCK(p p, s s, o o, ck ck) hexer.SPOCK {
+	return hexer.SPOCK{G: graphOf(hexer.Pattern(q)), S: s, P: p, O: o, C: ck.C, K: ck.K}
 }
 
 // executes query against ⟨p, o, s⟩ data structure
@@ -230,12 +231,12 @@ func (q queryPOS) L2(list *skiplist.SkipList[o, __p]) Seq[o, __p] {
 	return queryXSD[o](q.O, list)
 }
 
-func (q queryPOS) L3(list *skiplist.SkipList[s, k]) Seq[s, k] {
+func (q queryPOS) L3(list *skiplist.SkipList[s, ck]) Seq[s, ck] {
 	return queryIRI[s](q.S, list)
 }
 
-func (q queryPOS) ToSPOCK(p p, o o, s s) hexer.SPOCK {
-	return hexer.SPOCK{S: s, P: p, O: o}
+func (q queryPOS) ToSPOCK(p p, o o, s s, ck ck) hexer.SPOCK {
+	return hexer.SPOCK{G: graphOf(hexer.Pattern(q)), S: s, P: p, O: o, C: ck.C, K: ck.K}
 }
 
 // executes query against ⟨o, p, s⟩ data structure
@@ -249,12 +250,12 @@ func (q queryOPS) L2(list *skiplist.SkipList[p, __s]) Seq[p, __s] {
 	return queryIRI[p](q.P, list)
 }
 
-func (q queryOPS) L3(list *skiplist.SkipList[s, k]) Seq[s, k] {
+func (q queryOPS) L3(list *skiplist.SkipList[s, ck]) Seq[s, ck] {
 	return queryIRI[s](q.S, list)
 }
 
-func (q queryOPS) ToSPOCK(o o, p p, s s) hexer.SPOCK {
-	return hexer.SPOCK{S: s, P: p, O: o}
+func (q queryOPS) ToSPOCK(o o, p p, s s, ck ck) hexer.SPOCK {
+	return hexer.SPOCK{G: graphOf(hexer.Pattern(q)), S: s, P: p, O: o, C: ck.C, K: ck.K}
 }
 
 // executes query against ⟨o, s, p⟩ data structure
@@ -268,10 +269,10 @@ func (q queryOSP) L2(list *skiplist.SkipList[s, __p]) Seq[s, __p] {
 	return queryIRI[s](q.S, list)
 }
 
-func (q queryOSP) L3(list *skiplist.SkipList[p, k]) Seq[p, k] {
+func (q queryOSP) L3(list *skiplist.SkipList[p, ck]) Seq[p, ck] {
 	return queryIRI[p](q.P, list)
 }
 
-func (q queryOSP) ToSPOCK(o o, s s, p p) hexer.SPOCK {
-	return hexer.SPOCK{S: s, P: p, O: o}
+func (q queryOSP) ToSPOCK(o o, s s, p p, ck ck) hexer.SPOCK {
+	return hexer.SPOCK{G: graphOf(hexer.Pattern(q)), S: s, P: p, O: o, C: ck.C, K: ck.K}
 }
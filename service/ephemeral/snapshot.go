@@ -0,0 +1,120 @@
+package ephemeral
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+)
+
+//
+// The file implements snapshots and forks of the store. They share lists of
+// indexes with the store, the shared list is never modified. Lists are tagged
+// with epoch of the store, the share starts new epoch. The writer copies
+// lists of earlier epochs on the path of statement before the modification
+// (copy-on-write). Untouched lists remain shared between the store, its
+// snapshots and forks. Once all views are released, the store writes lists
+// in place unless they are shared with forks.
+//
+
+// View is read-only, point-in-time snapshot of the store
+type View struct {
+	store   *Store
+	origin  *Store
+	epoch   uint64 // lists of this or earlier epoch are shared with origin
+	release sync.Once
+}
+
+var _ hexer.Getter = (*View)(nil)
+
+// Snapshot creates consistent view of the store, statements written into
+// the store after the snapshot are not visible through the view. The store
+// copies lists shared with the view on write until the view is released.
+func Snapshot(store *Store) *View {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return snapshot(store)
+}
+
+func snapshot(store *Store) *View {
+	store.views++
+	return &View{
+		store:  share(store, nil),
+		origin: store,
+		epoch:  store.shared,
+	}
+}
+
+// Release the view, the store writes lists in place once all its views are
+// released. The view must not be used after release.
+func (view *View) Release() {
+	view.release.Do(func() {
+		store := view.origin
+		store.mu.Lock()
+		defer store.mu.Unlock()
+
+		store.views--
+		if store.views == 0 {
+			store.shared = store.pinned
+		}
+	})
+}
+
+// Size returns number of distinct knowledge statements in the view
+func (view *View) Size() int {
+	return view.store.size
+}
+
+// Match knowledge statements with the pattern
+func (view *View) Match(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
+	return view.store.Match(ctx, q)
+}
+
+// Fork creates independent writable copy of the store
+func Fork(store *Store) *Store {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	fork := share(store, rand.NewSource(time.Now().UnixNano()))
+	store.pinned = store.shared
+	return fork
+}
+
+// Fork creates independent writable copy of the view
+func (view *View) Fork() *Store {
+	// lists of the view remain shared with the fork after the view is released
+	store := view.origin
+	store.mu.Lock()
+	if store.pinned < view.epoch {
+		store.pinned = view.epoch
+	}
+	store.mu.Unlock()
+
+	return Fork(view.store)
+}
+
+// shares lists of the store with a new one, both stores copy lists of
+// current epoch on write.
+func share(store *Store, rnd rand.Source) *Store {
+	graphs := make(map[curie.IRI]*graph, len(store.graphs))
+	for g, graph := range store.graphs {
+		graphs[g] = graph
+	}
+
+	store.shared = store.epoch
+	store.epoch++
+
+	return &Store{
+		size:     store.size,
+		refreshK: store.refreshK,
+		random:   rnd,
+		graphs:   graphs,
+		epoch:    store.epoch,
+		shared:   store.shared,
+		pinned:   store.shared,
+	}
+}
@@ -1,7 +1,9 @@
 package ephemeral
 
 import (
+	"context"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/fogfish/curie"
@@ -11,137 +13,476 @@ import (
 	"github.com/fogfish/skiplist"
 )
 
-// Store is the instance of knowledge storage
+// Store is the instance of knowledge storage. The store is safe for
+// concurrent use by multiple goroutines. Writers are serialized, the statement
+// is written into all indexes atomically once it is committed to the log.
+// Readers are not blocked by open streams, streams are weakly consistent:
+// the statement is visible in all indexes or in none of them but the stream
+// might observe writes made after it has been opened.
 type Store struct {
-	size   int
-	random rand.Source
-	spo    spo
-	sop    sop
-	pso    pso
-	pos    pos
-	osp    osp
-	ops    ops
+	mu       sync.RWMutex
+	wmu      sync.Mutex // serializes writers, see write
+	size     int
+	refreshK bool
+	random   rand.Source
+	graphs   map[curie.IRI]*graph
+	epoch    uint64 // epoch of lists allocated by the store, see Snapshot
+	shared   uint64 // lists of this or earlier epoch are shared
+	pinned   uint64 // lists of this or earlier epoch are shared with forks
+	views    int    // number of views sharing lists with the store
+	log      *wal   // write-ahead log, see Open
+}
+
+// graph is an instance of hexastore, the named graph of knowledge statements
+// indexed in six ways. The default graph is identified by empty IRI.
+type graph struct {
+	epoch uint64
+	spo   spo
+	sop   sop
+	pso   pso
+	pos   pos
+	osp   osp
+	ops   ops
+}
+
+func newGraph(rnd rand.Source, epoch uint64) *graph {
+	return &graph{
+		epoch: epoch,
+		spo:   newSPO(rnd, epoch),
+		sop:   newSOP(rnd, epoch),
+		pso:   newPSO(rnd, epoch),
+		pos:   newPOS(rnd, epoch),
+		osp:   newOSP(rnd, epoch),
+		ops:   newOPS(rnd, epoch),
+	}
+}
+
+// lookup graph, the empty graph is returned if it do not exist
+func (store *Store) graph(g curie.IRI) *graph {
+	if graph, has := store.graphs[g]; has {
+		return graph
+	}
+
+	return newGraph(store.random, store.epoch)
+}
+
+// lookup graph writable by the store, the graph shared with views or forks
+// is copied, its lists are copied on write.
+func (store *Store) ensureGraph(g curie.IRI) *graph {
+	graph, has := store.graphs[g]
+	switch {
+	case !has:
+		graph = newGraph(store.random, store.epoch)
+		store.graphs[g] = graph
+	case graph.epoch <= store.shared:
+		copy := *graph
+		copy.epoch = store.epoch
+		graph = &copy
+		store.graphs[g] = graph
+	}
+
+	return graph
+}
+
+// Option of the store
+type Option func(*Store)
+
+// WithRefreshK configures the store to refresh k-order of statements
+// on re-insert. By default, the store keeps k-order of original statement.
+// The k-order is either supplied by the writer or assigned by the store.
+func WithRefreshK() Option {
+	return func(store *Store) { store.refreshK = true }
 }
 
 // Create new instance of knowledge storage
-func New() *Store {
+func New(opts ...Option) *Store {
 	rnd := rand.NewSource(time.Now().UnixNano())
-	return &Store{
+	store := &Store{
 		random: rnd,
-		spo:    newSPO(rnd),
-		sop:    newSOP(rnd),
-		pso:    newPSO(rnd),
-		pos:    newPOS(rnd),
-		osp:    newOSP(rnd),
-		ops:    newOPS(rnd),
+		graphs: map[curie.IRI]*graph{},
+		epoch:  1,
+	}
+
+	for _, opt := range opts {
+		opt(store)
 	}
+
+	return store
 }
 
-// Size returns number of knowledge statements in the store
+// Size returns number of distinct knowledge statements in the store
 func Size(store *Store) int {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
 	return store.size
 }
 
-func Add(store *Store, bag hexer.Bag) {
-	for _, spock := range bag {
-		Put(store, spock)
+// Add bag of knowledge statements into the store. The log is synced once
+// for the bag.
+func Add(store *Store, bag hexer.Bag) error {
+	_, _, err := write(context.Background(), store, opPut, bag)
+	return err
+}
+
+// Put knowledge statement into the store. The store has set semantic,
+// it returns true if statement is new and false if it already exists.
+// The statement is stamped with k-order unless it is supplied by caller.
+func Put(store *Store, spock hexer.SPOCK) (bool, error) {
+	_, n, err := write(context.Background(), store, opPut, hexer.Bag{spock})
+	return n == 1, err
+}
+
+// assigns k-order to statement, unless it is supplied by caller
+func ensureK(spock hexer.SPOCK) hexer.SPOCK {
+	if spock.K == (guid.K{}) {
+		spock.K = guid.L(guid.Clock)
 	}
+	return spock
 }
 
-func Put(store *Store, spock hexer.SPOCK) {
-	spock.K = guid.L(guid.Clock)
+// writes statements of the bag ahead to the log, indexes are updated once
+// the log is synced. Writers are serialized so that the log and indexes
+// follow same order, readers are blocked only while indexes are updated.
+// It returns statements, which are not written, and number of statements
+// changing the store.
+func write(ctx context.Context, store *Store, op byte, bag hexer.Bag) (hexer.Bag, int, error) {
+	store.wmu.Lock()
+	defer store.wmu.Unlock()
+
+	if err := store.log.failure(); err != nil {
+		return bag, 0, err
+	}
 
-	_po, _op := ensureForS(store, spock.S)
-	_so, _os := ensureForP(store, spock.P)
-	_sp, _ps := ensureForO(store, spock.O)
+	// statements are written up to the first invalid one
+	var err error
+	seq, at := make(hexer.Bag, 0, len(bag)), len(bag)
+	for i, spock := range bag {
+		if err = ctx.Err(); err != nil {
+			at = i
+			break
+		}
+
+		// indexes compare values, the data-type must be known
+		if err = xsd.Validate(spock.O); err != nil {
+			at = i
+			break
+		}
+
+		if op == opPut {
+			spock = ensureK(spock)
+		}
+
+		if changes(store, op, spock) {
+			seq = append(seq, spock)
+		}
+	}
+
+	if err := store.log.append(op, seq); err != nil {
+		return bag, 0, err
+	}
+
+	if err := store.log.sync(); err != nil {
+		return bag, 0, err
+	}
+
+	n := 0
+	store.mu.Lock()
+	for _, spock := range seq {
+		if (op == opPut && put(store, spock)) || (op == opCut && cut(store, spock)) {
+			n++
+		}
+	}
+	store.mu.Unlock()
+
+	if err != nil {
+		return bag[at:], n, err
+	}
+
+	return nil, n, nil
+}
+
+// checks if statement changes the store, other writes are not logged.
+// Indexes are not modified while writer is active.
+func changes(store *Store, op byte, spock hexer.SPOCK) bool {
+	graph, has := store.graphs[spock.G]
+	if op == opCut {
+		return has && exists(graph, spock)
+	}
+
+	return !has || !exists(graph, spock) || store.refreshK
+}
+
+// puts statement with given k-order into indexes, it returns true if
+// statement is new
+func put(store *Store, spock hexer.SPOCK) bool {
+	graph := store.ensureGraph(spock.G)
+
+	has := exists(graph, spock)
+	if has && !store.refreshK {
+		return false
+	}
+
+	index(store, graph, spock)
+
+	if has {
+		return false
+	}
+
+	store.size++
+	return true
+}
+
+// writes statement into all indexes of the graph
+func index(store *Store, graph *graph, spock hexer.SPOCK) {
+	_po, _op := ensureForS(store, graph, spock.S)
+	_so, _os := ensureForP(store, graph, spock.P)
+	_sp, _ps := ensureForO(store, graph, spock.O)
 
 	putO(store, _po, _so, spock)
 	putP(store, _op, _sp, spock)
 	putS(store, _os, _ps, spock)
-
-	store.size++
 }
 
-func ensureForS(store *Store, s curie.IRI) (_po, _op) {
-	_po, has := skiplist.Lookup(store.spo, s)
+// checks if statement exists in the graph
+func exists(graph *graph, spock hexer.SPOCK) bool {
+	_po, has := skiplist.Lookup(graph.spo.SkipList, spock.S)
 	if !has {
-		_po = newPO(store.random)
-		skiplist.Put(store.spo, s, _po)
+		return false
 	}
 
-	_op, has := skiplist.Lookup(store.sop, s)
+	__o, has := skiplist.Lookup(_po.SkipList, spock.P)
 	if !has {
-		_op = newOP(store.random)
-		skiplist.Put(store.sop, s, _op)
+		return false
 	}
-	return _po, _op
+
+	_, has = skiplist.Lookup(__o.SkipList, spock.O)
+	return has
 }
 
-func ensureForP(store *Store, p curie.IRI) (_so, _os) {
-	_so, has := skiplist.Lookup(store.pso, p)
-	if !has {
-		_so = newSO(store.random)
-		skiplist.Put(store.pso, p, _so)
+func ensureForS(store *Store, graph *graph, s curie.IRI) (_po, _op) {
+	graph.spo, _ = own(store, graph.spo, newSPO)
+	graph.sop, _ = own(store, graph.sop, newSOP)
+
+	return ownAt(store, graph.spo, s, newPO), ownAt(store, graph.sop, s, newOP)
+}
+
+func ensureForP(store *Store, graph *graph, p curie.IRI) (_so, _os) {
+	graph.pso, _ = own(store, graph.pso, newPSO)
+	graph.pos, _ = own(store, graph.pos, newPOS)
+
+	return ownAt(store, graph.pso, p, newSO), ownAt(store, graph.pos, p, newOS)
+}
+
+func ensureForO(store *Store, graph *graph, o xsd.Value) (_sp, _ps) {
+	graph.osp, _ = own(store, graph.osp, newOSP)
+	graph.ops, _ = own(store, graph.ops, newOPS)
+
+	return ownAt(store, graph.osp, o, newSP), ownAt(store, graph.ops, o, newPS)
+}
+
+func putO(store *Store, _po _po, _so _so, spock hexer.SPOCK) {
+	__o := ownLeaf(store, _po, spock.P, _so, spock.S, newO)
+	skiplist.Put(__o.SkipList, spock.O, ck{C: spock.C, K: spock.K})
+}
+
+func putP(store *Store, _op _op, _sp _sp, spock hexer.SPOCK) {
+	__p := ownLeaf(store, _sp, spock.S, _op, spock.O, newP)
+	skiplist.Put(__p.SkipList, spock.P, ck{C: spock.C, K: spock.K})
+}
+
+func putS(store *Store, _os _os, _ps _ps, spock hexer.SPOCK) {
+	__s := ownLeaf(store, _ps, spock.P, _os, spock.O, newS)
+	skiplist.Put(__s.SkipList, spock.S, ck{C: spock.C, K: spock.K})
+}
+
+// returns the list writable by the store, the list shared with views or
+// forks is copied, the missing list is allocated. It returns true if
+// the list is replaced.
+func own[K, V any](
+	store *Store,
+	seq list[K, V],
+	alloc func(rand.Source, uint64) list[K, V],
+) (list[K, V], bool) {
+	if seq.SkipList != nil && seq.epoch > store.shared {
+		return seq, false
 	}
 
-	_os, has := skiplist.Lookup(store.pos, p)
-	if !has {
-		_os = newOS(store.random)
-		skiplist.Put(store.pos, p, _os)
+	owned := alloc(store.random, store.epoch)
+	if seq.SkipList != nil {
+		skiplist.Values(seq.SkipList).FMap(func(key K, val V) error {
+			skiplist.Put(owned.SkipList, key, val)
+			return nil
+		})
 	}
-	return _so, _os
+
+	return owned, true
 }
 
-func ensureForO(store *Store, o xsd.Value) (_sp, _ps) {
-	_sp, has := skiplist.Lookup(store.osp, o)
-	if !has {
-		_sp = newSP(store.random)
-		skiplist.Put(store.osp, o, _sp)
+// returns the writable list of 2nd faction, the list replaces shared one
+func ownAt[A, B, C any](
+	store *Store,
+	parent list[A, list[B, C]],
+	key A,
+	alloc func(rand.Source, uint64) list[B, C],
+) list[B, C] {
+	seq, _ := skiplist.Lookup(parent.SkipList, key)
+	seq, replaced := own(store, seq, alloc)
+	if replaced {
+		skiplist.Put(parent.SkipList, key, seq)
 	}
 
-	_ps, has := skiplist.Lookup(store.ops, o)
-	if !has {
-		_ps = newPS(store.random)
-		skiplist.Put(store.ops, o, _ps)
+	return seq
+}
+
+// returns the writable list of 3rd faction, the list is shared by two indexes
+func ownLeaf[A, B, C any](
+	store *Store,
+	a list[A, list[C, ck]], keyA A,
+	b list[B, list[C, ck]], keyB B,
+	alloc func(rand.Source, uint64) list[C, ck],
+) list[C, ck] {
+	seq, _ := skiplist.Lookup(a.SkipList, keyA)
+	seq, replaced := own(store, seq, alloc)
+	if replaced {
+		skiplist.Put(a.SkipList, keyA, seq)
+		skiplist.Put(b.SkipList, keyB, seq)
 	}
-	return _sp, _ps
+
+	return seq
 }
 
-func putO(store *Store, _po _po, _so _so, spock hexer.SPOCK) {
-	__o, has := skiplist.Lookup(_po, spock.P)
-	if !has {
-		__o = newO(store.random)
-		skiplist.Put(_po, spock.P, __o)
-		skiplist.Put(_so, spock.S, __o)
+// Cut removes knowledge statement from the store.
+func Cut(store *Store, spock hexer.SPOCK) error {
+	_, _, err := write(context.Background(), store, opCut, hexer.Bag{spock})
+	return err
+}
+
+// cuts statement from indexes, it returns true if statement existed
+func cut(store *Store, spock hexer.SPOCK) bool {
+	graph, has := store.graphs[spock.G]
+	if !has || !exists(graph, spock) {
+		return false
 	}
 
-	skiplist.Put(__o, spock.O, spock.K)
+	graph = store.ensureGraph(spock.G)
+	cutO(store, graph, spock)
+	cutP(store, graph, spock)
+	cutS(store, graph, spock)
+
+	if skiplist.Length(graph.spo.SkipList) == 0 {
+		delete(store.graphs, spock.G)
+	}
+
+	store.size--
+	return true
 }
 
-func putP(store *Store, _op _op, _sp _sp, spock hexer.SPOCK) {
-	__p, has := skiplist.Lookup(_sp, spock.S)
-	if !has {
-		__p = newP(store.random)
-		skiplist.Put(_op, spock.O, __p)
-		skiplist.Put(_sp, spock.S, __p)
+// CutAll removes all knowledge statements matching the pattern
+func CutAll(store *Store, q hexer.Pattern) error {
+	seq, err := Match(store, q)
+	if err != nil {
+		return err
 	}
 
-	skiplist.Put(__p, spock.P, spock.K)
+	// indexes are not modified while the stream is consumed
+	bag := hexer.Bag{}
+	if err := seq.FMap(bag.Join); err != nil {
+		return err
+	}
+
+	_, _, err = write(context.Background(), store, opCut, bag)
+	return err
 }
 
-func putS(store *Store, _os _os, _ps _ps, spock hexer.SPOCK) {
-	__s, has := skiplist.Lookup(_ps, spock.P)
-	if !has {
-		__s = newS(store.random)
-		skiplist.Put(_os, spock.O, __s)
-		skiplist.Put(_ps, spock.P, __s)
+// removes ⟨s,p,o⟩ from spo and pso indexes, empty lists are pruned
+func cutO(store *Store, graph *graph, spock hexer.SPOCK) {
+	graph.spo, _ = own(store, graph.spo, newSPO)
+	graph.pso, _ = own(store, graph.pso, newPSO)
+	_po := ownAt(store, graph.spo, spock.S, newPO)
+	_so := ownAt(store, graph.pso, spock.P, newSO)
+	__o := ownLeaf(store, _po, spock.P, _so, spock.S, newO)
+
+	skiplist.Remove(__o.SkipList, spock.O)
+	if skiplist.Length(__o.SkipList) != 0 {
+		return
 	}
 
-	skiplist.Put(__s, spock.S, spock.K)
+	skiplist.Remove(_po.SkipList, spock.P)
+	if skiplist.Length(_po.SkipList) == 0 {
+		skiplist.Remove(graph.spo.SkipList, spock.S)
+	}
+
+	skiplist.Remove(_so.SkipList, spock.S)
+	if skiplist.Length(_so.SkipList) == 0 {
+		skiplist.Remove(graph.pso.SkipList, spock.P)
+	}
+}
+
+// removes ⟨s,o,p⟩ from sop and osp indexes, empty lists are pruned
+func cutP(store *Store, graph *graph, spock hexer.SPOCK) {
+	graph.sop, _ = own(store, graph.sop, newSOP)
+	graph.osp, _ = own(store, graph.osp, newOSP)
+	_op := ownAt(store, graph.sop, spock.S, newOP)
+	_sp := ownAt(store, graph.osp, spock.O, newSP)
+	__p := ownLeaf(store, _sp, spock.S, _op, spock.O, newP)
+
+	skiplist.Remove(__p.SkipList, spock.P)
+	if skiplist.Length(__p.SkipList) != 0 {
+		return
+	}
+
+	skiplist.Remove(_op.SkipList, spock.O)
+	if skiplist.Length(_op.SkipList) == 0 {
+		skiplist.Remove(graph.sop.SkipList, spock.S)
+	}
+
+	skiplist.Remove(_sp.SkipList, spock.S)
+	if skiplist.Length(_sp.SkipList) == 0 {
+		skiplist.Remove(graph.osp.SkipList, spock.O)
+	}
+}
+
+// removes ⟨p,o,s⟩ from pos and ops indexes, empty lists are pruned
+func cutS(store *Store, graph *graph, spock hexer.SPOCK) {
+	graph.pos, _ = own(store, graph.pos, newPOS)
+	graph.ops, _ = own(store, graph.ops, newOPS)
+	_os := ownAt(store, graph.pos, spock.P, newOS)
+	_ps := ownAt(store, graph.ops, spock.O, newPS)
+	__s := ownLeaf(store, _ps, spock.P, _os, spock.O, newS)
+
+	skiplist.Remove(__s.SkipList, spock.S)
+	if skiplist.Length(__s.SkipList) != 0 {
+		return
+	}
+
+	skiplist.Remove(_os.SkipList, spock.O)
+	if skiplist.Length(_os.SkipList) == 0 {
+		skiplist.Remove(graph.pos.SkipList, spock.P)
+	}
+
+	skiplist.Remove(_ps.SkipList, spock.P)
+	if skiplist.Length(_ps.SkipList) == 0 {
+		skiplist.Remove(graph.ops.SkipList, spock.O)
+	}
 }
 
 func Match(store *Store, q hexer.Pattern) (hexer.Stream, error) {
+	if q.G != nil && q.G.Clause != hexer.EQ {
+		return nil, &notSupported{q}
+	}
+
+	if err := validate(q.O); err != nil {
+		return nil, err
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
 	switch q.Strategy {
+	case hexer.STRATEGY_NONE:
+		// full scan of the graph, spo index visits every statement
+		return store.streamSPO(q)
 	case hexer.STRATEGY_SPO:
 		return store.streamSPO(q)
 	case hexer.STRATEGY_SOP:
@@ -155,6 +496,44 @@ func Match(store *Store, q hexer.Pattern) (hexer.Stream, error) {
 	case hexer.STRATEGY_OPS:
 		return store.streamOPS(q)
 	default:
-		panic("xxx")
+		return nil, &notSupported{q}
+	}
+}
+
+//------------------------------------------------------------------------------
+//
+// hexer.Store interface
+//
+//------------------------------------------------------------------------------
+
+var _ hexer.Store = (*Store)(nil)
+
+// Put knowledge statement into the store
+func (store *Store) Put(ctx context.Context, spock hexer.SPOCK) error {
+	_, _, err := write(ctx, store, opPut, hexer.Bag{spock})
+	return err
+}
+
+// Add bag of knowledge statements into the store. The log is synced once
+// for the bag, statements are indexed only if sync succeeds. It returns
+// statements, which are not written, starting from the first invalid one
+// or the whole bag if the log fails.
+func (store *Store) Add(ctx context.Context, bag hexer.Bag) (hexer.Bag, error) {
+	seq, _, err := write(ctx, store, opPut, bag)
+	return seq, err
+}
+
+// Cut knowledge statement from the store
+func (store *Store) Cut(ctx context.Context, spock hexer.SPOCK) error {
+	_, _, err := write(ctx, store, opCut, hexer.Bag{spock})
+	return err
+}
+
+// Match knowledge statements with the pattern
+func (store *Store) Match(ctx context.Context, q hexer.Pattern) (hexer.Stream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+
+	return Match(store, q)
 }
@@ -1,29 +1,64 @@
 package ephemeral
 
 import (
+	"fmt"
+
+	"github.com/fogfish/curie"
 	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
 )
 
+type notSupported struct{ hexer.Pattern }
+
+func (err notSupported) Error() string { return fmt.Sprintf("not supported %s", err.Pattern.Dump()) }
+func (notSupported) NotSupported()     {}
+
+// graph of the pattern, nil graph predicate is the default graph
+func graphOf(q hexer.Pattern) curie.IRI {
+	if q.G == nil {
+		return ""
+	}
+
+	return q.G.Value
+}
+
+// values of the predicate are compared with indexes, the data-type must be known
+func validate(pred *hexer.Predicate[xsd.Value]) error {
+	if pred == nil {
+		return nil
+	}
+
+	for _, v := range []xsd.Value{pred.Value, pred.Other} {
+		if v != nil {
+			if err := xsd.Validate(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (store *Store) streamSPO(q hexer.Pattern) (hexer.Stream, error) {
-	return newIterator[s, p, o](querySPO(q), store.spo), nil
+	return newIterator[s, p, o](store.mu.RLocker(), querySPO(q), store.graph(graphOf(q)).spo), nil
 }
 
 func (store *Store) streamSOP(q hexer.Pattern) (hexer.Stream, error) {
-	return newIterator[s, o, p](querySOP(q), store.sop), nil
+	return newIterator[s, o, p](store.mu.RLocker(), querySOP(q), store.graph(graphOf(q)).sop), nil
 }
 
 func (store *Store) streamPSO(q hexer.Pattern) (hexer.Stream, error) {
-	return newIterator[p, s, o](queryPSO(q), store.pso), nil
+	return newIterator[p, s, o](store.mu.RLocker(), queryPSO(q), store.graph(graphOf(q)).pso), nil
 }
 
 func (store *Store) streamPOS(q hexer.Pattern) (hexer.Stream, error) {
-	return newIterator[p, o, s](queryPOS(q), store.pos), nil
+	return newIterator[p, o, s](store.mu.RLocker(), queryPOS(q), store.graph(graphOf(q)).pos), nil
 }
 
 func (store *Store) streamOSP(q hexer.Pattern) (hexer.Stream, error) {
-	return newIterator[o, s, p](queryOSP(q), store.osp), nil
+	return newIterator[o, s, p](store.mu.RLocker(), queryOSP(q), store.graph(graphOf(q)).osp), nil
 }
 
 func (store *Store) streamOPS(q hexer.Pattern) (hexer.Stream, error) {
-	return newIterator[o, p, s](queryOPS(q), store.ops), nil
+	return newIterator[o, p, s](store.mu.RLocker(), queryOPS(q), store.graph(graphOf(q)).ops), nil
 }
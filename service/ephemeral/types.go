@@ -17,42 +17,55 @@ type o = xsd.Value // object
 type c = float64   // credibility
 type k = guid.K    // k-order
 
+// attributes ⟨c,k⟩ of statement, the value of 3rd faction
+type ck struct {
+	C c
+	K k
+}
+
+// list of index tagged with epoch of the store, which has allocated it.
+// The list of earlier epoch might be shared with views and forks, see Snapshot.
+type list[K, V any] struct {
+	*skiplist.SkipList[K, V]
+	epoch uint64
+}
+
 // index types for 3rd faction
-type __s = *skiplist.SkipList[s, k]
-type __p = *skiplist.SkipList[p, k]
-type __o = *skiplist.SkipList[o, k]
+type __s = list[s, ck]
+type __p = list[p, ck]
+type __o = list[o, ck]
 
 // index types for 2nd faction
-type _po = *skiplist.SkipList[p, __o]
-type _op = *skiplist.SkipList[o, __p]
-type _so = *skiplist.SkipList[s, __o]
-type _os = *skiplist.SkipList[o, __s]
-type _sp = *skiplist.SkipList[s, __p]
-type _ps = *skiplist.SkipList[p, __s]
+type _po = list[p, __o]
+type _op = list[o, __p]
+type _so = list[s, __o]
+type _os = list[o, __s]
+type _sp = list[s, __p]
+type _ps = list[p, __s]
 
 // triple indexes
-type spo = *skiplist.SkipList[s, _po]
-type sop = *skiplist.SkipList[s, _op]
-type pso = *skiplist.SkipList[p, _so]
-type pos = *skiplist.SkipList[p, _os]
-type osp = *skiplist.SkipList[o, _sp]
-type ops = *skiplist.SkipList[o, _ps]
+type spo = list[s, _po]
+type sop = list[s, _op]
+type pso = list[p, _so]
+type pos = list[p, _os]
+type osp = list[o, _sp]
+type ops = list[o, _ps]
 
 // allocators for indexes
-func newS(rnd rand.Source) __s { return skiplist.New[s, k](ord.IRI, rnd) }
-func newP(rnd rand.Source) __p { return skiplist.New[p, k](ord.IRI, rnd) }
-func newO(rnd rand.Source) __o { return skiplist.New[o, k](ord.XSD, rnd) }
-
-func newPO(rnd rand.Source) _po { return skiplist.New[p, __o](ord.IRI, rnd) }
-func newOP(rnd rand.Source) _op { return skiplist.New[o, __p](ord.XSD, rnd) }
-func newSO(rnd rand.Source) _so { return skiplist.New[s, __o](ord.IRI, rnd) }
-func newOS(rnd rand.Source) _os { return skiplist.New[o, __s](ord.XSD, rnd) }
-func newSP(rnd rand.Source) _sp { return skiplist.New[s, __p](ord.IRI, rnd) }
-func newPS(rnd rand.Source) _ps { return skiplist.New[p, __s](ord.IRI, rnd) }
-
-func newSPO(rnd rand.Source) spo { return skiplist.New[s, _po](ord.IRI, rnd) }
-func newSOP(rnd rand.Source) sop { return skiplist.New[s, _op](ord.IRI, rnd) }
-func newPSO(rnd rand.Source) pso { return skiplist.New[p, _so](ord.IRI, rnd) }
-func newPOS(rnd rand.Source) pos { return skiplist.New[p, _os](ord.IRI, rnd) }
-func newOSP(rnd rand.Source) osp { return skiplist.New[o, _sp](ord.XSD, rnd) }
-func newOPS(rnd rand.Source) ops { return skiplist.New[o, _ps](ord.XSD, rnd) }
+func newS(rnd rand.Source, epoch uint64) __s { return __s{skiplist.New[s, ck](ord.IRI, rnd), epoch} }
+func newP(rnd rand.Source, epoch uint64) __p { return __p{skiplist.New[p, ck](ord.IRI, rnd), epoch} }
+func newO(rnd rand.Source, epoch uint64) __o { return __o{skiplist.New[o, ck](ord.XSD, rnd), epoch} }
+
+func newPO(rnd rand.Source, epoch uint64) _po { return _po{skiplist.New[p, __o](ord.IRI, rnd), epoch} }
+func newOP(rnd rand.Source, epoch uint64) _op { return _op{skiplist.New[o, __p](ord.XSD, rnd), epoch} }
+func newSO(rnd rand.Source, epoch uint64) _so { return _so{skiplist.New[s, __o](ord.IRI, rnd), epoch} }
+func newOS(rnd rand.Source, epoch uint64) _os { return _os{skiplist.New[o, __s](ord.XSD, rnd), epoch} }
+func newSP(rnd rand.Source, epoch uint64) _sp { return _sp{skiplist.New[s, __p](ord.IRI, rnd), epoch} }
+func newPS(rnd rand.Source, epoch uint64) _ps { return _ps{skiplist.New[p, __s](ord.IRI, rnd), epoch} }
+
+func newSPO(rnd rand.Source, epoch uint64) spo { return spo{skiplist.New[s, _po](ord.IRI, rnd), epoch} }
+func newSOP(rnd rand.Source, epoch uint64) sop { return sop{skiplist.New[s, _op](ord.IRI, rnd), epoch} }
+func newPSO(rnd rand.Source, epoch uint64) pso { return pso{skiplist.New[p, _so](ord.IRI, rnd), epoch} }
+func newPOS(rnd rand.Source, epoch uint64) pos { return pos{skiplist.New[p, _os](ord.IRI, rnd), epoch} }
+func newOSP(rnd rand.Source, epoch uint64) osp { return osp{skiplist.New[o, _sp](ord.XSD, rnd), epoch} }
+func newOPS(rnd rand.Source, epoch uint64) ops { return ops{skiplist.New[o, _ps](ord.XSD, rnd), epoch} }
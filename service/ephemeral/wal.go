@@ -0,0 +1,503 @@
+package ephemeral
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/guid/v2"
+	"github.com/fogfish/hexer"
+)
+
+//
+// The file implements durable store: the snapshot and the write-ahead log
+// of statements written after the snapshot. The directory contains
+//
+//	snapshot           statements in binary format, see Save
+//	%020d.wal          segments of the log, replayed in order
+//
+// The segment is a sequence of records
+//
+//	record   uvarint n, n × byte payload, uint32 crc32c of payload
+//	payload  byte op, string g, s, p, type of o, lexical o, float64 c, k
+//
+// The log is synced before statements are indexed and the write is
+// acknowledged. Torn or corrupted record at the tail of the last segment is
+// the write lost on crash, the segment is truncated to the last valid
+// record. Replay of put and cut
+// is idempotent, statements written into the snapshot might be replayed again
+// if the store crashes during compaction.
+//
+
+const (
+	opPut byte = 1
+	opCut byte = 2
+)
+
+const snapshotFile = "snapshot"
+
+var (
+	errNoLog  = errors.New("ephemeral: store is not durable, see Open")
+	errClosed = errors.New("ephemeral: store is closed")
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// wal is the write-ahead log, the nil log is disabled
+type wal struct {
+	mu      sync.Mutex   // protects buffer and segment
+	rotate  sync.RWMutex // protects file from rotation while it is synced
+	compact sync.Mutex   // serializes compactions
+	dir     string
+	seq     uint64
+	file    *os.File
+	w       *bufio.Writer
+	buf     bytes.Buffer // payload of record
+	batch   bytes.Buffer // records of statements, see append
+	err     error        // the log is broken, writes are rejected
+}
+
+// Open creates durable store at the directory. The store is recovered from
+// the snapshot and the log. Writes are logged until the store is closed,
+// use Compact to fold the log into the snapshot.
+func Open(dir string, opts ...Option) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	store, err := loadSnapshot(dir, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := segmentsOf(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, seq := range segments {
+		if err := replay(store, dir, seq, i == len(segments)-1); err != nil {
+			return nil, err
+		}
+	}
+
+	seq := uint64(1)
+	if len(segments) > 0 {
+		seq = segments[len(segments)-1]
+	}
+
+	file, err := os.OpenFile(segmentFile(dir, seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	store.log = &wal{
+		dir:  dir,
+		seq:  seq,
+		file: file,
+		w:    bufio.NewWriter(file),
+	}
+
+	return store, nil
+}
+
+// Compact writes statements of the store into the snapshot and removes
+// the log. Writers are blocked only while the log is switched to new segment.
+func Compact(store *Store) error {
+	log := store.log
+	if log == nil {
+		return errNoLog
+	}
+
+	log.compact.Lock()
+	defer log.compact.Unlock()
+
+	// writes in progress are either in the snapshot or in new segment
+	store.wmu.Lock()
+	store.mu.Lock()
+	view := snapshot(store)
+	seq, err := log.next()
+	store.mu.Unlock()
+	store.wmu.Unlock()
+	defer view.Release()
+
+	if err != nil {
+		return err
+	}
+
+	if err := writeSnapshot(log.dir, view); err != nil {
+		return err
+	}
+
+	segments, err := segmentsOf(log.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range segments {
+		if s < seq {
+			if err := os.Remove(segmentFile(log.dir, s)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Close flushes the log and releases its file. The closed store rejects
+// writes, statements remain readable. It is no-op for non-durable store.
+func Close(store *Store) error {
+	return store.log.close()
+}
+
+//------------------------------------------------------------------------------
+
+// writes records of statements into the buffer, records are not durable
+// until the log is synced. Either all records are buffered or none of them,
+// the log is not modified if any statement fails to encode.
+func (log *wal) append(op byte, bag hexer.Bag) error {
+	if log == nil {
+		return nil
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if log.err != nil {
+		return log.err
+	}
+
+	log.batch.Reset()
+	for _, spock := range bag {
+		if err := log.record(op, spock); err != nil {
+			return err
+		}
+	}
+
+	_, log.err = log.w.Write(log.batch.Bytes())
+	return log.err
+}
+
+// encodes record of the statement into the batch
+func (log *wal) record(op byte, spock hexer.SPOCK) error {
+	o, err := termOf(spock.O)
+	if err != nil {
+		return err
+	}
+
+	log.buf.Reset()
+	log.buf.WriteByte(op)
+	enc := &encoder{w: &log.buf}
+	enc.string(string(spock.G))
+	enc.string(string(spock.S))
+	enc.string(string(spock.P))
+	enc.string(string(o.dt))
+	enc.string(o.lex)
+	enc.float64(spock.C)
+	enc.uvarint(spock.K.Hi)
+	enc.uvarint(spock.K.Lo)
+	enc.bool(spock.K.Local)
+
+	payload := log.buf.Bytes()
+	var crc [4]byte
+	binary.LittleEndian.PutUint32(crc[:], crc32.Checksum(payload, crc32c))
+
+	enc = &encoder{w: &log.batch}
+	enc.uvarint(uint64(len(payload)))
+	enc.write(payload)
+	enc.write(crc[:])
+
+	return enc.err
+}
+
+// returns failure of the log, the broken or closed log rejects writes
+func (log *wal) failure() error {
+	if log == nil {
+		return nil
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	return log.err
+}
+
+// flushes buffer and commits the segment to disk
+func (log *wal) sync() error {
+	if log == nil {
+		return nil
+	}
+
+	log.rotate.RLock()
+	defer log.rotate.RUnlock()
+
+	log.mu.Lock()
+	if log.err == nil {
+		log.err = log.w.Flush()
+	}
+	err, file := log.err, log.file
+	log.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		log.mu.Lock()
+		log.err = err
+		log.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// switches log to new segment, the previous one is committed to disk.
+// It returns sequence number of new segment.
+func (log *wal) next() (uint64, error) {
+	log.rotate.Lock()
+	defer log.rotate.Unlock()
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if log.err != nil {
+		return 0, log.err
+	}
+
+	if err := log.commit(); err != nil {
+		log.err = err
+		return 0, err
+	}
+
+	seq := log.seq + 1
+	file, err := os.OpenFile(segmentFile(log.dir, seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.err = err
+		return 0, err
+	}
+	syncDir(log.dir)
+
+	log.seq = seq
+	log.file = file
+	log.w = bufio.NewWriter(file)
+	return seq, nil
+}
+
+func (log *wal) close() error {
+	if log == nil {
+		return nil
+	}
+
+	log.rotate.Lock()
+	defer log.rotate.Unlock()
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if log.err == errClosed {
+		return nil
+	}
+
+	err := log.err
+	if err == nil {
+		err = log.commit()
+	} else {
+		log.file.Close()
+	}
+	log.err = errClosed
+
+	return err
+}
+
+// flushes, syncs and closes the file of current segment
+func (log *wal) commit() error {
+	if err := log.w.Flush(); err != nil {
+		log.file.Close()
+		return err
+	}
+
+	if err := log.file.Sync(); err != nil {
+		log.file.Close()
+		return err
+	}
+
+	return log.file.Close()
+}
+
+//------------------------------------------------------------------------------
+
+func segmentFile(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d.wal", seq))
+}
+
+// sequence numbers of segments in ascending order
+func segmentsOf(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []uint64{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".wal")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, seq)
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	return segments, nil
+}
+
+func loadSnapshot(dir string, opts ...Option) (*Store, error) {
+	file, err := os.Open(filepath.Join(dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return New(opts...), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Load(file, opts...)
+}
+
+// writes snapshot into temporary file, which replaces the snapshot once
+// it is committed to disk
+func writeSnapshot(dir string, view *View) error {
+	path := filepath.Join(dir, snapshotFile)
+
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	err = view.Save(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// commits directory entries to disk, it is not supported by all platforms
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// replays the segment over the store. The torn tail of the last segment is
+// truncated, the corruption of other segments fails the replay.
+func replay(store *Store, dir string, seq uint64, last bool) error {
+	path := segmentFile(dir, seq)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	pos := 0
+	for pos < len(data) {
+		payload, n := record(data[pos:])
+		if n == 0 {
+			if !last {
+				return fmt.Errorf("ephemeral: corrupted log %s at %d", filepath.Base(path), pos)
+			}
+			return os.Truncate(path, int64(pos))
+		}
+
+		op, spock, err := decodeRecord(payload)
+		if err != nil {
+			return fmt.Errorf("ephemeral: log %s at %d: %w", filepath.Base(path), pos, err)
+		}
+
+		switch op {
+		case opPut:
+			put(store, spock)
+		case opCut:
+			cut(store, spock)
+		}
+
+		pos += n
+	}
+
+	return nil
+}
+
+// parses record, it returns payload and length of record, the length is 0
+// if record is torn or corrupted
+func record(data []byte) ([]byte, int) {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) || uint64(len(data)-n)-size < 4 {
+		return nil, 0
+	}
+
+	payload := data[n : n+int(size)]
+	crc := binary.LittleEndian.Uint32(data[n+int(size):])
+	if crc32.Checksum(payload, crc32c) != crc {
+		return nil, 0
+	}
+
+	return payload, n + int(size) + 4
+}
+
+func decodeRecord(payload []byte) (byte, hexer.SPOCK, error) {
+	if len(payload) == 0 || (payload[0] != opPut && payload[0] != opCut) {
+		return 0, hexer.SPOCK{}, errFormat
+	}
+
+	op := payload[0]
+	dec := &decoder{r: bytes.NewReader(payload[1:])}
+
+	spock := hexer.SPOCK{
+		G: curie.IRI(dec.string()),
+		S: curie.IRI(dec.string()),
+		P: curie.IRI(dec.string()),
+	}
+	o := term{dt: curie.IRI(dec.string()), lex: dec.string()}
+	spock.C = dec.float64()
+	spock.K = guid.K{Hi: dec.uvarint(), Lo: dec.uvarint(), Local: dec.bool()}
+	if dec.err != nil {
+		return 0, hexer.SPOCK{}, dec.err
+	}
+
+	v, err := o.value()
+	if err != nil {
+		return 0, hexer.SPOCK{}, err
+	}
+	spock.O = v
+
+	return op, spock, nil
+}
@@ -0,0 +1,200 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package sparql
+
+import (
+	"fmt"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+//
+// The file compiles WHERE clause into basic graph pattern. FILTER
+// constraints on variables are pushed down to predicates of patterns.
+// Predicates must not reject solutions accepted by the FILTER, they might
+// accept more solutions because FILTER expressions are evaluated anyway.
+//
+
+// constraint of variable, the op is either comparison or ^ for prefix
+type constraint struct {
+	op    string
+	value xsd.Value
+	str   bool
+}
+
+func (p *parser) compile() error {
+	constraints := map[string][]constraint{}
+	for _, f := range p.filters {
+		for _, e := range conjunctsOf(f) {
+			if v, c, ok := constraintOf(e); ok {
+				constraints[v] = append(constraints[v], c)
+			}
+		}
+	}
+
+	for _, t := range p.triples {
+		s, err := predicateIRI(t.s, constraints)
+		if err != nil {
+			return err
+		}
+
+		pp, err := predicateIRI(t.p, constraints)
+		if err != nil {
+			return err
+		}
+
+		o := predicateValue(t.o, constraints)
+
+		p.query.Patterns = append(p.query.Patterns, hexer.Query(s, pp, o))
+	}
+
+	p.query.filters = p.filters
+	return nil
+}
+
+func conjunctsOf(e expr) []expr {
+	if x, ok := e.(and); ok {
+		return append(conjunctsOf(x.a), conjunctsOf(x.b)...)
+	}
+	return []expr{e}
+}
+
+var flip = map[string]string{"=": "=", "<": ">", ">": "<", "<=": ">=", ">=": "<="}
+
+func constraintOf(e expr) (string, constraint, bool) {
+	switch x := e.(type) {
+	case cmp:
+		op, has := flip[x.op]
+		switch {
+		case !has:
+			return "", constraint{}, false
+		case x.a.isVar() && !x.a.str && !x.b.isVar():
+			return x.a.Var, constraint{op: x.op, value: x.b.Value}, true
+		case x.b.isVar() && !x.b.str && !x.a.isVar():
+			return x.b.Var, constraint{op: op, value: x.a.Value}, true
+		}
+	case strStarts:
+		if x.a.isVar() {
+			return x.a.Var, constraint{op: "^", value: xsd.String(x.prefix), str: x.a.str}, true
+		}
+	case regex:
+		if x.a.isVar() && x.prefix != "" {
+			return x.a.Var, constraint{op: "^", value: xsd.String(x.prefix), str: x.a.str}, true
+		}
+	}
+
+	return "", constraint{}, false
+}
+
+// the predicate at subject or predicate position
+func predicateIRI(t term, constraints map[string][]constraint) (*hexer.Predicate[curie.IRI], error) {
+	if !t.isVar() {
+		iri, ok := t.Value.(xsd.AnyURI)
+		if !ok {
+			return nil, fmt.Errorf("sparql: literal %v is not allowed at subject or predicate", t.Value)
+		}
+		return hexer.IRI.Eq(curie.IRI(iri)), nil
+	}
+
+	pred := hexer.IRI.Var(t.Var)
+
+	// IRIs are compared as strings, it is matched by index only if
+	// constant is IRI. The prefix is defined either on IRI or str(IRI).
+	iriOf := func(c constraint) (curie.IRI, bool) {
+		switch v := c.value.(type) {
+		case xsd.AnyURI:
+			return curie.IRI(v), c.op != "^"
+		case xsd.String:
+			return curie.IRI(v), c.op == "^"
+		}
+		return "", false
+	}
+
+	seq := constraints[t.Var]
+	if c, ok := find(seq, "=", iriOf); ok {
+		pred.Clause, pred.Value = hexer.EQ, c
+	} else if from, to, ok := rangeOf(seq, iriOf); ok {
+		pred.Clause, pred.Value, pred.Other = hexer.IN, from, to
+	} else if c, ok := find(seq, "^", iriOf); ok {
+		pred.Clause, pred.Value = hexer.PQ, c
+	} else if c, ok := find(seq, "<", iriOf); ok {
+		pred.Clause, pred.Value = hexer.LT, c
+	} else if c, ok := find(seq, ">", iriOf); ok {
+		pred.Clause, pred.Value = hexer.GT, c
+	}
+
+	return pred, nil
+}
+
+// the predicate at object position
+func predicateValue(t term, constraints map[string][]constraint) *hexer.Predicate[xsd.Value] {
+	if !t.isVar() {
+		return hexer.Value.Eq(t.Value)
+	}
+
+	pred := hexer.Var(t.Var)
+
+	// number or instant equals to values of other data types (e.g. 1 = 1.0),
+	// index matches data type exactly. Prefix of string matches also
+	// language-tagged strings, index matches xsd:string only.
+	valueOf := func(c constraint) (xsd.Value, bool) {
+		switch c.op {
+		case "=", "<=", ">=":
+			return c.value, isExact(c.value)
+		case "^":
+			return nil, false
+		default:
+			return c.value, true
+		}
+	}
+
+	seq := constraints[t.Var]
+	if c, ok := find(seq, "=", valueOf); ok {
+		pred.Clause, pred.Value = hexer.EQ, c
+	} else if from, to, ok := rangeOf(seq, valueOf); ok && xsd.SameKind(from, to) {
+		pred.Clause, pred.Value, pred.Other = hexer.IN, from, to
+	} else if c, ok := find(seq, "<", valueOf); ok {
+		pred.Clause, pred.Value = hexer.LT, c
+	} else if c, ok := find(seq, ">", valueOf); ok {
+		pred.Clause, pred.Value = hexer.GT, c
+	}
+
+	return pred
+}
+
+func find[T any](seq []constraint, op string, f func(constraint) (T, bool)) (T, bool) {
+	for _, c := range seq {
+		if c.op == op {
+			if v, ok := f(c); ok {
+				return v, true
+			}
+		}
+	}
+
+	var none T
+	return none, false
+}
+
+func rangeOf[T any](seq []constraint, f func(constraint) (T, bool)) (T, T, bool) {
+	from, okf := find(seq, ">=", f)
+	to, okt := find(seq, "<=", f)
+	return from, to, okf && okt
+}
+
+// data types which are equal only to values of same data type
+func isExact(x xsd.Value) bool {
+	switch x.(type) {
+	case xsd.AnyURI, xsd.String, xsd.LangString, xsd.Boolean, xsd.Duration:
+		return true
+	default:
+		return false
+	}
+}
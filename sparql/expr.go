@@ -0,0 +1,201 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package sparql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+// expr is FILTER expression evaluated over the solution. Following SPARQL,
+// the expression that raises an error (e.g. unbound variable, comparison of
+// incompatible values) is false.
+type expr interface {
+	test(hexer.Binding) bool
+}
+
+type and struct{ a, b expr }
+
+func (e and) test(b hexer.Binding) bool { return e.a.test(b) && e.b.test(b) }
+
+type or struct{ a, b expr }
+
+func (e or) test(b hexer.Binding) bool { return e.a.test(b) || e.b.test(b) }
+
+type not struct{ a expr }
+
+func (e not) test(b hexer.Binding) bool { return !e.a.test(b) }
+
+type bound struct{ v string }
+
+func (e bound) test(b hexer.Binding) bool {
+	_, has := b[e.v]
+	return has
+}
+
+// operand of expression, str(?x) is the lexical form of variable
+type operand struct {
+	term
+	str bool
+}
+
+func (x operand) value(b hexer.Binding) (xsd.Value, bool) {
+	if !x.isVar() {
+		return x.Value, true
+	}
+
+	v, has := b[x.Var]
+	if !has {
+		return nil, false
+	}
+
+	if x.str {
+		return xsd.String(lexicalOf(v)), true
+	}
+
+	return v, true
+}
+
+// string argument of string functions, the IRI requires str(?x)
+func (x operand) string(b hexer.Binding) (string, bool) {
+	v, has := x.value(b)
+	if !has {
+		return "", false
+	}
+
+	switch v := v.(type) {
+	case xsd.String:
+		return string(v), true
+	case xsd.LangString:
+		return v.Value, true
+	default:
+		return "", false
+	}
+}
+
+type cmp struct {
+	op   string
+	a, b operand
+}
+
+func (e cmp) test(b hexer.Binding) bool {
+	x, ok := e.a.value(b)
+	if !ok {
+		return false
+	}
+
+	y, ok := e.b.value(b)
+	if !ok {
+		return false
+	}
+
+	c, ok := compare(x, y)
+	if !ok {
+		// incompatible values are not equal, but not ordered
+		return e.op == "!="
+	}
+
+	switch e.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case ">":
+		return c > 0
+	case "<=":
+		return c <= 0
+	case ">=":
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+type strStarts struct {
+	a      operand
+	prefix string
+}
+
+func (e strStarts) test(b hexer.Binding) bool {
+	s, ok := e.a.string(b)
+	return ok && strings.HasPrefix(s, e.prefix)
+}
+
+type regex struct {
+	a      operand
+	re     *regexp.Regexp
+	prefix string // literal prefix of anchored pattern, if any
+}
+
+func (e regex) test(b hexer.Binding) bool {
+	s, ok := e.a.string(b)
+	return ok && e.re.MatchString(s)
+}
+
+// compares values, numbers are compared by value regardless of data type
+func compare(a, b xsd.Value) (int, bool) {
+	if x, ok := a.(xsd.Integer); ok {
+		if y, ok := b.(xsd.Integer); ok {
+			return xsd.Compare(x, y), true
+		}
+	}
+
+	x, okx := numberOf(a)
+	y, oky := numberOf(b)
+	if okx && oky {
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	if !xsd.SameKind(a, b) {
+		return 0, false
+	}
+
+	return xsd.Compare(a, b), true
+}
+
+func numberOf(x xsd.Value) (float64, bool) {
+	switch v := x.(type) {
+	case xsd.Integer:
+		return float64(v), true
+	case xsd.Decimal:
+		return float64(v), true
+	case xsd.Float:
+		return float64(v), true
+	case xsd.Double:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func lexicalOf(x xsd.Value) string {
+	switch v := x.(type) {
+	case xsd.AnyURI:
+		return string(v)
+	case xsd.String:
+		return string(v)
+	case xsd.LangString:
+		return v.Value
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,291 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package sparql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// kind of lexical tokens
+type kind int
+
+const (
+	tEOF    kind = iota
+	tIRI         // <http://example.com/>
+	tPName       // prefixed name, e.g. foaf:name
+	tVar         // ?x or $x
+	tString      // "literal" or 'literal'
+	tNumber      // 1, 1.5, 1e3
+	tLang        // @en
+	tType        // ^^
+	tWord        // keywords, functions and booleans
+	tPunct       // { } ( ) . ; , *
+	tOp          // = != < > <= >= && || !
+)
+
+type token struct {
+	kind kind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q at %d", t.text, t.pos)
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func tokenize(input string) ([]token, error) {
+	lex := &lexer{input: input}
+	seq := []token{}
+
+	for {
+		tkn, err := lex.next()
+		if err != nil {
+			return nil, err
+		}
+
+		seq = append(seq, tkn)
+		if tkn.kind == tEOF {
+			return seq, nil
+		}
+	}
+}
+
+func (lex *lexer) peek(n int) byte {
+	if lex.pos+n < len(lex.input) {
+		return lex.input[lex.pos+n]
+	}
+	return 0
+}
+
+func (lex *lexer) skip() {
+	for lex.pos < len(lex.input) {
+		c := lex.input[lex.pos]
+		switch {
+		case c == '#':
+			for lex.pos < len(lex.input) && lex.input[lex.pos] != '\n' {
+				lex.pos++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			lex.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (lex *lexer) next() (token, error) {
+	lex.skip()
+
+	at := lex.pos
+	if at >= len(lex.input) {
+		return token{kind: tEOF, pos: at}, nil
+	}
+
+	c := lex.input[at]
+	switch {
+	case c == '<':
+		if iri, ok := lex.iri(); ok {
+			return token{kind: tIRI, text: iri, pos: at}, nil
+		}
+		return lex.op(at)
+	case c == '?' || c == '$':
+		lex.pos++
+		name := lex.name()
+		if name == "" {
+			return token{}, fmt.Errorf("invalid variable at %d", at)
+		}
+		return token{kind: tVar, text: name, pos: at}, nil
+	case c == '"' || c == '\'':
+		return lex.string(at)
+	case c == '@':
+		lex.pos++
+		tag := lex.lang()
+		if tag == "" {
+			return token{}, fmt.Errorf("invalid language tag at %d", at)
+		}
+		return token{kind: tLang, text: tag, pos: at}, nil
+	case c == '^' && lex.peek(1) == '^':
+		lex.pos += 2
+		return token{kind: tType, text: "^^", pos: at}, nil
+	case strings.IndexByte("{}().;,*", c) != -1:
+		// decimal might start with dot, e.g. .5
+		if c == '.' && isDigit(lex.peek(1)) {
+			return lex.number(at)
+		}
+		lex.pos++
+		return token{kind: tPunct, text: string(c), pos: at}, nil
+	case strings.IndexByte("=!><&|", c) != -1:
+		return lex.op(at)
+	case isDigit(c) || ((c == '+' || c == '-') && (isDigit(lex.peek(1)) || lex.peek(1) == '.')):
+		return lex.number(at)
+	default:
+		return lex.word(at)
+	}
+}
+
+// IRIREF is <...> without white spaces, otherwise it is operator <
+func (lex *lexer) iri() (string, bool) {
+	for i := lex.pos + 1; i < len(lex.input); i++ {
+		switch c := lex.input[i]; {
+		case c == '>':
+			iri := lex.input[lex.pos+1 : i]
+			lex.pos = i + 1
+			return iri, true
+		case c <= ' ' || strings.IndexByte("<\"{}|^`\\", c) != -1:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func (lex *lexer) op(at int) (token, error) {
+	for _, op := range []string{"<=", ">=", "!=", "&&", "||", "=", "<", ">", "!"} {
+		if strings.HasPrefix(lex.input[at:], op) {
+			lex.pos = at + len(op)
+			return token{kind: tOp, text: op, pos: at}, nil
+		}
+	}
+	return token{}, fmt.Errorf("unexpected %q at %d", lex.input[at], at)
+}
+
+func (lex *lexer) string(at int) (token, error) {
+	quote := lex.input[at]
+	sb := strings.Builder{}
+
+	for i := at + 1; i < len(lex.input); i++ {
+		c := lex.input[i]
+		switch {
+		case c == quote:
+			lex.pos = i + 1
+			return token{kind: tString, text: sb.String(), pos: at}, nil
+		case c == '\\' && i+1 < len(lex.input):
+			i++
+			switch e := lex.input[i]; e {
+			case 't':
+				sb.WriteByte('\t')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			default:
+				sb.WriteByte(e)
+			}
+		case c == '\n':
+			return token{}, fmt.Errorf("unterminated string at %d", at)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return token{}, fmt.Errorf("unterminated string at %d", at)
+}
+
+func (lex *lexer) number(at int) (token, error) {
+	i := at
+	if c := lex.input[i]; c == '+' || c == '-' {
+		i++
+	}
+
+	digits := func() {
+		for i < len(lex.input) && isDigit(lex.input[i]) {
+			i++
+		}
+	}
+
+	digits()
+	if i < len(lex.input) && lex.input[i] == '.' && i+1 < len(lex.input) && isDigit(lex.input[i+1]) {
+		i++
+		digits()
+	}
+	if i < len(lex.input) && (lex.input[i] == 'e' || lex.input[i] == 'E') {
+		i++
+		if i < len(lex.input) && (lex.input[i] == '+' || lex.input[i] == '-') {
+			i++
+		}
+		digits()
+	}
+
+	lex.pos = i
+	return token{kind: tNumber, text: lex.input[at:i], pos: at}, nil
+}
+
+// keywords, booleans, function names, prefixed names and `a`
+func (lex *lexer) word(at int) (token, error) {
+	prefix := lex.name()
+
+	if lex.pos < len(lex.input) && lex.input[lex.pos] == ':' {
+		lex.pos++
+		local := lex.local()
+		return token{kind: tPName, text: prefix + ":" + local, pos: at}, nil
+	}
+
+	if prefix == "" {
+		r, _ := utf8.DecodeRuneInString(lex.input[at:])
+		return token{}, fmt.Errorf("unexpected %q at %d", r, at)
+	}
+
+	return token{kind: tWord, text: prefix, pos: at}, nil
+}
+
+func (lex *lexer) name() string {
+	at := lex.pos
+	for lex.pos < len(lex.input) {
+		r, n := utf8.DecodeRuneInString(lex.input[lex.pos:])
+		if !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || (lex.pos > at && r == '-')) {
+			break
+		}
+		lex.pos += n
+	}
+	return lex.input[at:lex.pos]
+}
+
+// local part of prefixed name, it might contain dots but not at the end
+func (lex *lexer) local() string {
+	at := lex.pos
+	for lex.pos < len(lex.input) {
+		r, n := utf8.DecodeRuneInString(lex.input[lex.pos:])
+		if !(r == '_' || r == '-' || r == '.' || r == '%' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			break
+		}
+		lex.pos += n
+	}
+	for lex.pos > at && lex.input[lex.pos-1] == '.' {
+		lex.pos--
+	}
+	return lex.input[at:lex.pos]
+}
+
+func (lex *lexer) lang() string {
+	at := lex.pos
+	for lex.pos < len(lex.input) {
+		c := lex.input[lex.pos]
+		if !(c == '-' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')) {
+			break
+		}
+		lex.pos++
+	}
+	return lex.input[at:lex.pos]
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
@@ -0,0 +1,599 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package sparql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer/xsd"
+)
+
+// term of triple pattern or expression, either variable or constant.
+// IRIs are represented as xsd.AnyURI.
+type term struct {
+	Var   string
+	Value xsd.Value
+}
+
+func (t term) isVar() bool { return t.Var != "" }
+
+// triple pattern of WHERE clause
+type triple struct{ s, p, o term }
+
+// recursive descent parser of SPARQL SELECT queries
+type parser struct {
+	seq      []token
+	pos      int
+	prefixes curie.Namespaces
+	triples  []triple
+	filters  []expr
+	query    *Query
+}
+
+func (p *parser) head() token { return p.seq[p.pos] }
+
+func (p *parser) take() token {
+	tkn := p.seq[p.pos]
+	if tkn.kind != tEOF {
+		p.pos++
+	}
+	return tkn
+}
+
+// checks if head is the keyword (case-insensitive)
+func (p *parser) isWord(word string) bool {
+	tkn := p.head()
+	return tkn.kind == tWord && strings.EqualFold(tkn.text, word)
+}
+
+func (p *parser) is(kind kind, text string) bool {
+	tkn := p.head()
+	return tkn.kind == kind && tkn.text == text
+}
+
+func (p *parser) expectWord(word string) error {
+	if !p.isWord(word) {
+		return p.unexpected(word)
+	}
+	p.take()
+	return nil
+}
+
+func (p *parser) expect(kind kind, text string) error {
+	if !p.is(kind, text) {
+		return p.unexpected(text)
+	}
+	p.take()
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	return fmt.Errorf("sparql: expected %s, got %s", expected, p.head())
+}
+
+//
+// Query := Prologue Select Where Modifiers
+//
+
+func (p *parser) parseQuery() error {
+	if err := p.parsePrologue(); err != nil {
+		return err
+	}
+
+	if err := p.parseSelect(); err != nil {
+		return err
+	}
+
+	if err := p.parseWhere(); err != nil {
+		return err
+	}
+
+	if err := p.parseModifiers(); err != nil {
+		return err
+	}
+
+	if p.head().kind != tEOF {
+		return p.unexpected("end of query")
+	}
+
+	return nil
+}
+
+// Prologue := ( 'PREFIX' PNAME_NS IRIREF )*
+func (p *parser) parsePrologue() error {
+	for p.isWord("PREFIX") {
+		p.take()
+
+		ns := p.take()
+		if ns.kind != tPName || !strings.HasSuffix(ns.text, ":") {
+			return fmt.Errorf("sparql: expected prefix, got %s", ns)
+		}
+
+		iri := p.take()
+		if iri.kind != tIRI {
+			return fmt.Errorf("sparql: expected IRI, got %s", iri)
+		}
+
+		p.prefixes[strings.TrimSuffix(ns.text, ":")] = iri.text
+	}
+
+	return nil
+}
+
+// Select := 'SELECT' 'DISTINCT'? ( Var+ | '*' )
+func (p *parser) parseSelect() error {
+	if err := p.expectWord("SELECT"); err != nil {
+		return err
+	}
+
+	if p.isWord("DISTINCT") {
+		p.take()
+		p.query.Distinct = true
+	}
+
+	if p.is(tPunct, "*") {
+		p.take()
+		return nil
+	}
+
+	for p.head().kind == tVar {
+		p.query.Vars = append(p.query.Vars, p.take().text)
+	}
+
+	if len(p.query.Vars) == 0 {
+		return p.unexpected("variable or *")
+	}
+
+	return nil
+}
+
+// Where := 'WHERE'? '{' ( Triples | Filter )* '}'
+func (p *parser) parseWhere() error {
+	if p.isWord("WHERE") {
+		p.take()
+	}
+
+	if err := p.expect(tPunct, "{"); err != nil {
+		return err
+	}
+
+	for !p.is(tPunct, "}") {
+		switch {
+		case p.head().kind == tEOF:
+			return p.unexpected("}")
+		case p.is(tPunct, "."):
+			p.take()
+		case p.isWord("FILTER"):
+			p.take()
+			e, err := p.parseConstraint()
+			if err != nil {
+				return err
+			}
+			p.filters = append(p.filters, e)
+		default:
+			if err := p.parseTriples(); err != nil {
+				return err
+			}
+		}
+	}
+
+	p.take()
+	return nil
+}
+
+// Triples := Subject Verb ObjectList ( ';' Verb ObjectList )*
+func (p *parser) parseTriples() error {
+	s, err := p.parseTerm()
+	if err != nil {
+		return err
+	}
+
+	for {
+		v, err := p.parseVerb()
+		if err != nil {
+			return err
+		}
+
+		// ObjectList := Object ( ',' Object )*
+		for {
+			o, err := p.parseTerm()
+			if err != nil {
+				return err
+			}
+			p.triples = append(p.triples, triple{s: s, p: v, o: o})
+
+			if !p.is(tPunct, ",") {
+				break
+			}
+			p.take()
+		}
+
+		if !p.is(tPunct, ";") {
+			return nil
+		}
+		p.take()
+
+		// trailing ; is allowed
+		if p.is(tPunct, ".") || p.is(tPunct, "}") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseVerb() (term, error) {
+	if p.is(tWord, "a") {
+		p.take()
+		return term{Value: xsd.AnyURI("rdf:type")}, nil
+	}
+
+	return p.parseTerm()
+}
+
+// Term := Var | IRI | Literal
+func (p *parser) parseTerm() (term, error) {
+	tkn := p.take()
+
+	switch tkn.kind {
+	case tVar:
+		return term{Var: tkn.text}, nil
+	case tIRI:
+		return term{Value: xsd.AnyURI(curie.FromURI(p.prefixes, tkn.text))}, nil
+	case tPName:
+		// blank nodes in query are non-distinguished variables
+		if strings.HasPrefix(tkn.text, "_:") {
+			return term{Var: tkn.text}, nil
+		}
+		return term{Value: xsd.AnyURI(tkn.text)}, nil
+	case tString:
+		return p.parseLiteral(tkn.text)
+	case tNumber:
+		return parseNumber(tkn)
+	case tWord:
+		switch strings.ToLower(tkn.text) {
+		case "true":
+			return term{Value: xsd.Boolean(true)}, nil
+		case "false":
+			return term{Value: xsd.Boolean(false)}, nil
+		}
+	}
+
+	return term{}, fmt.Errorf("sparql: expected term, got %s", tkn)
+}
+
+func (p *parser) parseLiteral(lexical string) (term, error) {
+	switch {
+	case p.head().kind == tLang:
+		return term{Value: xsd.Lang(lexical, p.take().text)}, nil
+	case p.head().kind == tType:
+		p.take()
+
+		dt := p.take()
+		var iri curie.IRI
+		switch dt.kind {
+		case tIRI:
+			iri = curie.FromURI(p.prefixes, dt.text)
+		case tPName:
+			iri = curie.IRI(dt.text)
+		default:
+			return term{}, fmt.Errorf("sparql: expected data type, got %s", dt)
+		}
+
+		v, err := xsd.Parse(iri, lexical)
+		if err != nil {
+			return term{}, fmt.Errorf("sparql: %w", err)
+		}
+		return term{Value: v}, nil
+	default:
+		return term{Value: xsd.String(lexical)}, nil
+	}
+}
+
+func parseNumber(tkn token) (term, error) {
+	switch {
+	case strings.ContainsAny(tkn.text, "eE"):
+		v, err := strconv.ParseFloat(tkn.text, 64)
+		if err != nil {
+			return term{}, fmt.Errorf("sparql: invalid number %s", tkn)
+		}
+		return term{Value: xsd.Double(v)}, nil
+	case strings.Contains(tkn.text, "."):
+		v, err := strconv.ParseFloat(tkn.text, 64)
+		if err != nil {
+			return term{}, fmt.Errorf("sparql: invalid number %s", tkn)
+		}
+		return term{Value: xsd.Decimal(v)}, nil
+	default:
+		v, err := strconv.ParseInt(tkn.text, 10, 64)
+		if err != nil {
+			return term{}, fmt.Errorf("sparql: invalid number %s", tkn)
+		}
+		return term{Value: xsd.Integer(v)}, nil
+	}
+}
+
+//
+// Constraint := '(' Expr ')' | Call
+// Expr := And ( '||' And )*
+// And  := Unary ( '&&' Unary )*
+// Unary := '!' Unary | Primary
+// Primary := '(' Expr ')' | Call | Operand Op Operand
+//
+
+func (p *parser) parseConstraint() (expr, error) {
+	if p.is(tPunct, "(") {
+		p.take()
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(tPunct, ")")
+	}
+
+	return p.parseCall()
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	a, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is(tOp, "||") {
+		p.take()
+		b, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		a = or{a, b}
+	}
+
+	return a, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	a, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.is(tOp, "&&") {
+		p.take()
+		b, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		a = and{a, b}
+	}
+
+	return a, nil
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.is(tOp, "!") {
+		p.take()
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{e}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	switch {
+	case p.is(tPunct, "("):
+		p.take()
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(tPunct, ")")
+	case p.isWord("regex") || p.isWord("strStarts") || p.isWord("bound"):
+		return p.parseCall()
+	}
+
+	a, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.head().kind != tOp || p.is(tOp, "&&") || p.is(tOp, "||") || p.is(tOp, "!") {
+		return nil, p.unexpected("comparison")
+	}
+
+	op := p.take().text
+	b, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return cmp{op: op, a: a, b: b}, nil
+}
+
+// Operand := Term | 'str' '(' Var ')'
+func (p *parser) parseOperand() (operand, error) {
+	if p.isWord("str") {
+		p.take()
+		if err := p.expect(tPunct, "("); err != nil {
+			return operand{}, err
+		}
+
+		tkn := p.take()
+		if tkn.kind != tVar {
+			return operand{}, fmt.Errorf("sparql: expected variable, got %s", tkn)
+		}
+
+		return operand{term: term{Var: tkn.text}, str: true}, p.expect(tPunct, ")")
+	}
+
+	t, err := p.parseTerm()
+	if err != nil {
+		return operand{}, err
+	}
+
+	return operand{term: t}, nil
+}
+
+// Call := 'regex' '(' Operand ',' String ( ',' String )? ')'
+//
+//	| 'strStarts' '(' Operand ',' String ')'
+//	| 'bound' '(' Var ')'
+func (p *parser) parseCall() (expr, error) {
+	fn := p.take()
+	if fn.kind != tWord {
+		return nil, fmt.Errorf("sparql: expected function, got %s", fn)
+	}
+
+	if err := p.expect(tPunct, "("); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(fn.text) {
+	case "bound":
+		tkn := p.take()
+		if tkn.kind != tVar {
+			return nil, fmt.Errorf("sparql: expected variable, got %s", tkn)
+		}
+		return bound{tkn.text}, p.expect(tPunct, ")")
+
+	case "strstarts":
+		a, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(tPunct, ","); err != nil {
+			return nil, err
+		}
+
+		prefix := p.take()
+		if prefix.kind != tString {
+			return nil, fmt.Errorf("sparql: expected string, got %s", prefix)
+		}
+
+		return strStarts{a: a, prefix: prefix.text}, p.expect(tPunct, ")")
+
+	case "regex":
+		a, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(tPunct, ","); err != nil {
+			return nil, err
+		}
+
+		pat := p.take()
+		if pat.kind != tString {
+			return nil, fmt.Errorf("sparql: expected string, got %s", pat)
+		}
+
+		flags := ""
+		if p.is(tPunct, ",") {
+			p.take()
+			f := p.take()
+			if f.kind != tString {
+				return nil, fmt.Errorf("sparql: expected string, got %s", f)
+			}
+			flags = f.text
+		}
+
+		if err := p.expect(tPunct, ")"); err != nil {
+			return nil, err
+		}
+
+		return newRegex(a, pat.text, flags)
+	}
+
+	return nil, fmt.Errorf("sparql: function %s is not supported", fn)
+}
+
+func newRegex(a operand, pattern, flags string) (expr, error) {
+	re := pattern
+	for _, f := range flags {
+		switch f {
+		case 'i', 's', 'm':
+			re = "(?" + string(f) + ")" + re
+		default:
+			return nil, fmt.Errorf("sparql: regex flag %q is not supported", f)
+		}
+	}
+
+	rx, err := regexp.Compile(re)
+	if err != nil {
+		return nil, fmt.Errorf("sparql: %w", err)
+	}
+
+	e := regex{a: a, re: rx}
+
+	// anchored literal pattern is prefix query
+	if flags == "" && strings.HasPrefix(pattern, "^") && regexp.QuoteMeta(pattern[1:]) == pattern[1:] {
+		e.prefix = pattern[1:]
+	}
+
+	return e, nil
+}
+
+// Modifiers := ( 'ORDER' 'BY' Order+ )? ( 'LIMIT' n | 'OFFSET' n )*
+func (p *parser) parseModifiers() error {
+	if p.isWord("ORDER") {
+		p.take()
+		if err := p.expectWord("BY"); err != nil {
+			return err
+		}
+
+		for p.head().kind == tVar || p.isWord("ASC") || p.isWord("DESC") {
+			if p.head().kind == tVar {
+				p.query.Order = append(p.query.Order, Order{Var: p.take().text})
+				continue
+			}
+
+			desc := strings.EqualFold(p.take().text, "DESC")
+			if err := p.expect(tPunct, "("); err != nil {
+				return err
+			}
+			tkn := p.take()
+			if tkn.kind != tVar {
+				return fmt.Errorf("sparql: expected variable, got %s", tkn)
+			}
+			if err := p.expect(tPunct, ")"); err != nil {
+				return err
+			}
+			p.query.Order = append(p.query.Order, Order{Var: tkn.text, Desc: desc})
+		}
+
+		if len(p.query.Order) == 0 {
+			return p.unexpected("order condition")
+		}
+	}
+
+	for p.isWord("LIMIT") || p.isWord("OFFSET") {
+		word := strings.ToUpper(p.take().text)
+
+		tkn := p.take()
+		n, err := strconv.Atoi(tkn.text)
+		if tkn.kind != tNumber || err != nil || n < 0 {
+			return fmt.Errorf("sparql: expected non-negative integer, got %s", tkn)
+		}
+
+		if word == "LIMIT" {
+			p.query.Limit = n
+		} else {
+			p.query.Offset = n
+		}
+	}
+
+	return nil
+}
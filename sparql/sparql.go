@@ -0,0 +1,298 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+// Package sparql implements a practical subset of SPARQL 1.1 SELECT queries:
+// PREFIX, SELECT [DISTINCT] with projection, WHERE basic graph patterns,
+// FILTER with comparison, regex, strStarts and bound, ORDER BY, LIMIT and
+// OFFSET. The query is compiled into hexer patterns, FILTER constraints on
+// variables are pushed down to patterns as EQ, PQ, LT, GT and IN predicates
+// and evaluated by the store indexes. FILTER expressions are also evaluated
+// over solutions, so that SPARQL semantic is preserved.
+//
+// IRIs are compact (e.g. foaf:name), absolute IRIs of query are compacted
+// using PREFIX declarations.
+//
+// Prefix filters (strStarts, anchored regex) on object position are matched
+// against xsd:string values by the store indexes.
+package sparql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer"
+	"github.com/fogfish/hexer/xsd"
+)
+
+// Query is SELECT query compiled into basic graph pattern
+type Query struct {
+	Vars     []string        // projection, empty for SELECT *
+	Distinct bool            // eliminates duplicate solutions
+	Patterns []hexer.Pattern // basic graph pattern
+	Order    []Order         // ORDER BY conditions
+	Limit    int             // max number of solutions, -1 if not defined
+	Offset   int             // number of solutions to skip
+	filters  []expr
+}
+
+// Order condition of solutions
+type Order struct {
+	Var  string
+	Desc bool
+}
+
+// Parse SPARQL SELECT query
+func Parse(query string) (*Query, error) {
+	seq, err := tokenize(query)
+	if err != nil {
+		return nil, errors.New("sparql: " + err.Error())
+	}
+
+	p := &parser{
+		seq:      seq,
+		prefixes: curie.Namespaces{},
+		query:    &Query{Limit: -1},
+	}
+
+	if err := p.parseQuery(); err != nil {
+		return nil, err
+	}
+
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+
+	return p.query, nil
+}
+
+// Select parses and evaluates SPARQL SELECT query against the store
+func Select(ctx context.Context, store hexer.Getter, query string) (hexer.Bindings, error) {
+	q, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return Eval(ctx, store, q)
+}
+
+// Eval evaluates the query against the store
+func Eval(ctx context.Context, store hexer.Getter, q *Query) (hexer.Bindings, error) {
+	seq, err := hexer.Join(ctx, store, q.Patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := q.filters
+	if len(q.Order) > 0 {
+		bag := []hexer.Binding{}
+		err := seq.FMap(func(b hexer.Binding) error {
+			if accept(filters, b) {
+				bag = append(bag, b)
+			}
+			return nil
+		})
+		seq.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		sort.SliceStable(bag, func(i, j int) bool { return less(q.Order, bag[i], bag[j]) })
+		seq, filters = &slice{seq: bag}, nil
+	}
+
+	return &solutions{
+		seq:      seq,
+		filters:  filters,
+		vars:     q.Vars,
+		distinct: q.Distinct,
+		seen:     map[string]struct{}{},
+		offset:   q.Offset,
+		limit:    q.Limit,
+	}, nil
+}
+
+func accept(filters []expr, b hexer.Binding) bool {
+	for _, f := range filters {
+		if !f.test(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// unbound variables are ordered before bound ones
+func less(order []Order, a, b hexer.Binding) bool {
+	for _, o := range order {
+		x, hasx := a[o.Var]
+		y, hasy := b[o.Var]
+
+		c := 0
+		switch {
+		case !hasx && !hasy:
+			c = 0
+		case !hasx:
+			c = -1
+		case !hasy:
+			c = 1
+		default:
+			var ok bool
+			if c, ok = compare(x, y); !ok {
+				c = xsd.Compare(x, y)
+			}
+		}
+
+		if o.Desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c < 0
+		}
+	}
+
+	return false
+}
+
+//------------------------------------------------------------------------------
+
+// stream of sorted solutions
+type slice struct {
+	seq  []hexer.Binding
+	head hexer.Binding
+}
+
+func (s *slice) Head() hexer.Binding { return s.head }
+
+func (s *slice) Next() bool {
+	if len(s.seq) == 0 {
+		return false
+	}
+
+	s.head, s.seq = s.seq[0], s.seq[1:]
+	return true
+}
+
+func (s *slice) FMap(f func(hexer.Binding) error) error {
+	for s.Next() {
+		if err := f(s.Head()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *slice) Err() error { return nil }
+
+func (s *slice) Close() error {
+	s.seq = nil
+	return nil
+}
+
+// stream of solutions: filter, projection, distinct, offset and limit
+type solutions struct {
+	seq      hexer.Bindings
+	filters  []expr
+	vars     []string
+	distinct bool
+	seen     map[string]struct{}
+	offset   int
+	limit    int
+	head     hexer.Binding
+}
+
+var errLimit = errors.New("limit")
+
+func (s *solutions) Head() hexer.Binding { return s.head }
+
+func (s *solutions) Next() bool {
+	if s.limit == 0 {
+		return false
+	}
+
+	for s.seq.Next() {
+		if b, ok := s.accept(s.seq.Head()); ok {
+			s.head = b
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *solutions) FMap(f func(hexer.Binding) error) error {
+	if s.limit == 0 {
+		return nil
+	}
+
+	err := s.seq.FMap(func(b hexer.Binding) error {
+		b, ok := s.accept(b)
+		if !ok {
+			return nil
+		}
+
+		if err := f(b); err != nil {
+			return err
+		}
+
+		if s.limit == 0 {
+			return errLimit
+		}
+		return nil
+	})
+
+	if err == errLimit {
+		return s.seq.Close()
+	}
+	return err
+}
+
+func (s *solutions) Err() error { return s.seq.Err() }
+
+func (s *solutions) Close() error { return s.seq.Close() }
+
+func (s *solutions) accept(b hexer.Binding) (hexer.Binding, bool) {
+	if !accept(s.filters, b) {
+		return nil, false
+	}
+
+	if len(s.vars) != 0 {
+		b = b.Project(s.vars...)
+	}
+
+	if s.distinct {
+		key := keyOf(b)
+		if _, has := s.seen[key]; has {
+			return nil, false
+		}
+		s.seen[key] = struct{}{}
+	}
+
+	if s.offset > 0 {
+		s.offset--
+		return nil, false
+	}
+
+	if s.limit > 0 {
+		s.limit--
+	}
+
+	return b, true
+}
+
+// identity of solution, values are distinguished by data type
+func keyOf(b hexer.Binding) string {
+	sb := strings.Builder{}
+	for _, k := range b.Vars() {
+		sb.WriteString(fmt.Sprintf("%s\x00%s\x00%v\x00", k, b[k].XSDType(), b[k]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package sparql_test
+
+import (
+	"testing"
+
+	"github.com/fogfish/hexer/sparql"
+	"github.com/fogfish/it/v2"
+)
+
+func TestParse(t *testing.T) {
+	Parse := func(t *testing.T, query string) (*sparql.Query, it.SeqOf[string]) {
+		t.Helper()
+		q, err := sparql.Parse(query)
+		it.Then(t).Should(it.Nil(err))
+
+		seq := []string{}
+		for _, p := range q.Patterns {
+			seq = append(seq, p.Dump())
+		}
+
+		return q, it.Seq(seq)
+	}
+
+	t.Run("Select", func(t *testing.T) {
+		q, seq := Parse(t, `
+			PREFIX foaf: <http://xmlns.com/foaf/0.1/>
+			SELECT DISTINCT ?x ?name
+			WHERE {
+				?x a foaf:Person ;
+					foaf:name ?name .
+				?x <http://xmlns.com/foaf/0.1/knows> ?y , ?z .
+			}
+			ORDER BY DESC(?name) ?x
+			LIMIT 10 OFFSET 5
+		`)
+
+		it.Then(t).Should(
+			it.Seq(q.Vars).Equal("x", "name"),
+			it.True(q.Distinct),
+			it.Seq(q.Order).Equal(sparql.Order{Var: "name", Desc: true}, sparql.Order{Var: "x"}),
+			it.Equal(q.Limit, 10),
+			it.Equal(q.Offset, 5),
+			seq.Equal(
+				"⟪(po) ⇒ s : s ?x, p = rdf:type, o = [foaf:Person]⟫",
+				"⟪(p) ⇒ so : s ?x, p = foaf:name, o ?name⟫",
+				"⟪(p) ⇒ so : s ?x, p = foaf:knows, o ?y⟫",
+				"⟪(p) ⇒ so : s ?x, p = foaf:knows, o ?z⟫",
+			),
+		)
+	})
+
+	t.Run("SelectAll", func(t *testing.T) {
+		q, seq := Parse(t, `SELECT * { ?s ?p ?o }`)
+
+		it.Then(t).Should(
+			it.Seq(q.Vars).BeEmpty(),
+			it.Equal(q.Limit, -1),
+			seq.Equal("⟪(___) ⇒ ∅ : s ?s, p ?p, o ?o⟫"),
+		)
+	})
+
+	t.Run("Literals", func(t *testing.T) {
+		_, seq := Parse(t, `SELECT * {
+			?s ?p "text" , "text"@en , 'quote\'s' , 42 , -1.5 , 1e3 , true ,
+				"2023-01-02"^^xsd:date ,
+				"7"^^<http://www.w3.org/2001/XMLSchema#integer> .
+		}`)
+
+		it.Then(t).Should(
+			seq.Equal(
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = "text"⟫`,
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = "text"@en⟫`,
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = "quote's"⟫`,
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = 42⟫`,
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = -1.5⟫`,
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = 1000⟫`,
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = true⟫`,
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = 2023-01-02⟫`,
+				`⟪(o) ⇒ ps : s ?s, p ?p, o = 7⟫`,
+			),
+		)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		_, seq := Parse(t, `SELECT ?x {
+			?x <u:name> ?name ; <u:age> ?age ; <u:city> ?city ; <u:zip> ?zip ; <u:tag> ?tag .
+			?y <u:knows> ?x .
+			FILTER (?age >= 18 && ?age < 65)
+			FILTER strStarts(?name, "J")
+			FILTER (?city >= "A" && ?city <= "M")
+			FILTER (10 < ?zip)
+			FILTER regex(?tag, "^go")
+			FILTER (?x = <u:A> || ?x = <u:B>)
+			FILTER strStarts(str(?y), "u:")
+		}`)
+
+		it.Then(t).Should(
+			seq.Equal(
+				`⟪(p) ⇒ so : s ?x, p = u:name, o ?name⟫`,
+				`⟪(p)º ⇒ s : s ?x, p = u:age, o ?age < 65⟫`,
+				`⟪(p)º ⇒ s : s ?x, p = u:city, o ?city ["A", "M"]⟫`,
+				`⟪(p)º ⇒ s : s ?x, p = u:zip, o ?zip > 10⟫`,
+				`⟪(p) ⇒ so : s ?x, p = u:tag, o ?tag⟫`,
+				`⟪(pˢ) ⇒ o : s ?y ~ u:, p = u:knows, o ?x⟫`,
+			),
+		)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for _, query := range []string{
+			`SELECT ?x`,
+			`SELECT { ?x ?p ?o }`,
+			`SELECT ?x { ?x ?p }`,
+			`SELECT ?x { ?x ?p ?o`,
+			`SELECT ?x { "a" ?p ?o }`,
+			`SELECT ?x { ?x ?p "a }`,
+			`SELECT ?x { ?x ?p ?o FILTER (?o) }`,
+			`SELECT ?x { ?x ?p ?o FILTER lang(?o) }`,
+			`SELECT ?x { ?x ?p "1"^^xsd:integer2 }`,
+			`SELECT ?x { ?x ?p ?o } LIMIT x`,
+			`PREFIX foaf <http://xmlns.com/foaf/0.1/> SELECT ?x { ?x ?p ?o }`,
+		} {
+			_, err := sparql.Parse(query)
+			it.Then(t).ShouldNot(it.Nil(err))
+		}
+	})
+}
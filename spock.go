@@ -10,12 +10,16 @@ import (
 
 // Knowledge statement
 //
+//	g: graph
 //	s: subject
 //	p: predicate
 //	o: object
 //	c: credibility
 //	k: k-order
+//
+// The statement belongs to the default graph if g is empty.
 type SPOCK struct {
+	G curie.IRI
 	S curie.IRI
 	P curie.IRI
 	O xsd.Value
@@ -24,6 +28,9 @@ type SPOCK struct {
 }
 
 func (spock SPOCK) String() string {
+	if spock.G != "" {
+		return fmt.Sprintf("⟨%s %s %s %s⟩", spock.S.Safe(), spock.P.Safe(), spock.O, spock.G.Safe())
+	}
 	return fmt.Sprintf("⟨%s %s %s⟩", spock.S.Safe(), spock.P.Safe(), spock.O)
 }
 
@@ -32,6 +39,16 @@ func From[T xsd.DataType](s, p curie.IRI, o T) SPOCK {
 	return SPOCK{S: s, P: p, O: xsd.From(o)}
 }
 
+// Create new knowledge statement in the named graph
+func Quad[T xsd.DataType](g, s, p curie.IRI, o T) SPOCK {
+	return SPOCK{G: g, S: s, P: p, O: xsd.From(o)}
+}
+
+// Create new knowledge statement with language-tagged string literal
+func FromLang(s, p curie.IRI, text, lang string) SPOCK {
+	return SPOCK{S: s, P: p, O: xsd.Lang(text, lang)}
+}
+
 // Collection of knowledge statements
 type Bag []SPOCK
 
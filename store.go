@@ -0,0 +1,28 @@
+package hexer
+
+import "context"
+
+// Getter is a reader of knowledge statements
+type Getter interface {
+	// Match knowledge statements with the pattern
+	Match(context.Context, Pattern) (Stream, error)
+}
+
+// Putter is a writer of knowledge statements
+type Putter interface {
+	// Put knowledge statement into the store
+	Put(context.Context, SPOCK) error
+
+	// Add bag of knowledge statements into the store. It returns statements
+	// that are not written due to failure.
+	Add(context.Context, Bag) (Bag, error)
+
+	// Cut knowledge statement from the store
+	Cut(context.Context, SPOCK) error
+}
+
+// Store is the backend neutral knowledge storage
+type Store interface {
+	Getter
+	Putter
+}
@@ -7,11 +7,53 @@ import (
 	"github.com/fogfish/hexer/xsd"
 )
 
-// Stream of knowledge statements ⟨s,p,o,c,k⟩
+// Stream of knowledge statements ⟨s,p,o,c,k⟩. The stream returns false from
+// Next either at the end or on failure, the failure is reported by Err.
+// FMap returns the failure of the stream after all statements are consumed.
+// Close releases the stream, it is safe to close the stream many times.
 type Stream interface {
 	Head() SPOCK
 	Next() bool
 	FMap(func(SPOCK) error) error
+	Err() error
+	Close() error
+}
+
+type bag struct {
+	seq  Bag
+	head SPOCK
+}
+
+func (bag *bag) Head() SPOCK { return bag.head }
+
+func (bag *bag) Next() bool {
+	if len(bag.seq) == 0 {
+		return false
+	}
+
+	bag.head, bag.seq = bag.seq[0], bag.seq[1:]
+	return true
+}
+
+func (bag *bag) FMap(f func(SPOCK) error) error {
+	for bag.Next() {
+		if err := f(bag.Head()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bag *bag) Err() error { return nil }
+
+func (bag *bag) Close() error {
+	bag.seq = nil
+	return nil
+}
+
+// NewStream creates stream of knowledge statements from the bag
+func NewStream(seq Bag) Stream {
+	return &bag{seq: seq}
 }
 
 type filter struct {
@@ -41,9 +83,13 @@ func (filter *filter) FMap(f func(SPOCK) error) error {
 			return err
 		}
 	}
-	return nil
+	return filter.stream.Err()
 }
 
+func (filter *filter) Err() error { return filter.stream.Err() }
+
+func (filter *filter) Close() error { return filter.stream.Close() }
+
 func NewFilter(pred func(SPOCK) bool, stream Stream) Stream {
 	return &filter{pred: pred, stream: stream}
 }
@@ -64,12 +110,16 @@ func NewFilterO(hint Hint, q *Predicate[xsd.Value], stream Stream) Stream {
 		switch q.Clause {
 		case LT:
 			return NewFilter(
-				func(spock SPOCK) bool { return xsd.Compare(spock.O, q.Value) == -1 },
+				func(spock SPOCK) bool {
+					return xsd.SameKind(spock.O, q.Value) && xsd.Compare(spock.O, q.Value) == -1
+				},
 				stream,
 			)
 		case GT:
 			return NewFilter(
-				func(spock SPOCK) bool { return xsd.Compare(spock.O, q.Value) == 1 },
+				func(spock SPOCK) bool {
+					return xsd.SameKind(spock.O, q.Value) && xsd.Compare(spock.O, q.Value) == 1
+				},
 				stream,
 			)
 		case IN:
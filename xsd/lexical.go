@@ -0,0 +1,191 @@
+package xsd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fogfish/curie"
+)
+
+// namespace of XML Schema data types
+const xmlSchema = "http://www.w3.org/2001/XMLSchema#"
+
+// Parse value from the lexical form of data type (e.g. "42"^^xsd:integer).
+// Data types are identified either by compact IRI (xsd:integer) or by
+// the absolute one. User-defined data-types are decoded using the registry.
+func Parse(dt curie.IRI, lexical string) (Value, error) {
+	if strings.HasPrefix(string(dt), xmlSchema) {
+		dt = curie.IRI("xsd:" + string(dt)[len(xmlSchema):])
+	}
+
+	switch dt {
+	case XSD_ANYURI:
+		return AnyURI(lexical), nil
+	case XSD_STRING:
+		return String(lexical), nil
+	case XSD_INTEGER, "xsd:int", "xsd:long", "xsd:short", "xsd:byte",
+		"xsd:nonNegativeInteger", "xsd:positiveInteger",
+		"xsd:nonPositiveInteger", "xsd:negativeInteger",
+		"xsd:unsignedInt", "xsd:unsignedLong", "xsd:unsignedShort", "xsd:unsignedByte":
+		v, err := strconv.ParseInt(lexical, 10, 64)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return Integer(v), nil
+	case XSD_DECIMAL:
+		v, err := strconv.ParseFloat(lexical, 64)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return Decimal(v), nil
+	case XSD_FLOAT:
+		v, err := strconv.ParseFloat(lexical, 32)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return Float(v), nil
+	case XSD_DOUBLE:
+		v, err := strconv.ParseFloat(lexical, 64)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return Double(v), nil
+	case XSD_BOOLEAN:
+		switch lexical {
+		case "true", "1":
+			return Boolean(true), nil
+		case "false", "0":
+			return Boolean(false), nil
+		}
+		return nil, errLexical(dt, lexical)
+	case XSD_DATETIME:
+		v, err := time.Parse(time.RFC3339Nano, lexical)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return DateTime(v), nil
+	case XSD_DATE:
+		v, err := time.Parse(time.DateOnly, lexical)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return Date(v), nil
+	case XSD_DURATION:
+		v, err := parseDuration(lexical)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return Duration(v), nil
+	case XSD_HEXBINARY:
+		v, err := hex.DecodeString(lexical)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return HexBinary(v), nil
+	case XSD_BASE64BINARY:
+		v, err := base64.StdEncoding.DecodeString(lexical)
+		if err != nil {
+			return nil, errLexical(dt, lexical)
+		}
+		return Base64Binary(v), nil
+	}
+
+	return Decode(dt, lexical)
+}
+
+// Lexical returns the lexical form of value, it is inverse of Parse.
+// User-defined data-types are encoded using the registry.
+func Lexical(x Value) (string, error) {
+	switch v := x.(type) {
+	case AnyURI:
+		return string(v), nil
+	case String:
+		return string(v), nil
+	case LangString:
+		return v.Value, nil
+	case Float:
+		return lexicalFloat(float64(v), 32), nil
+	case Double:
+		return lexicalFloat(float64(v), 64), nil
+	case Integer, Decimal, Boolean, DateTime, Date, Duration, HexBinary, Base64Binary:
+		return v.(fmt.Stringer).String(), nil
+	}
+
+	return Encode(x)
+}
+
+// special values of floating point are INF, -INF and NaN
+func lexicalFloat(v float64, bitSize int) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "INF"
+	case math.IsInf(v, -1):
+		return "-INF"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, bitSize)
+}
+
+func errLexical(dt curie.IRI, lexical string) error {
+	return fmt.Errorf("invalid lexical form %q of %s", lexical, dt)
+}
+
+// parses ISO 8601 duration (e.g. P1DT1H30M1.5S), years and months
+// are not supported due to variable length.
+func parseDuration(lexical string) (time.Duration, error) {
+	s := lexical
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) < 2 {
+		return 0, fmt.Errorf("invalid duration %s", lexical)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			inTime, s = true, s[1:]
+			continue
+		}
+
+		at := strings.IndexAny(s, "DHMS")
+		if at <= 0 {
+			return 0, fmt.Errorf("invalid duration %s", lexical)
+		}
+
+		n, err := strconv.ParseFloat(s[:at], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %s", lexical)
+		}
+
+		var unit time.Duration
+		switch {
+		case s[at] == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case s[at] == 'H' && inTime:
+			unit = time.Hour
+		case s[at] == 'M' && inTime:
+			unit = time.Minute
+		case s[at] == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %s", lexical)
+		}
+
+		d += time.Duration(n * float64(unit))
+		s = s[at+1:]
+	}
+
+	return sign * d, nil
+}
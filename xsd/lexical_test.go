@@ -0,0 +1,104 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package xsd_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer/xsd"
+	"github.com/fogfish/it/v2"
+)
+
+func TestParse(t *testing.T) {
+	Parse := func(dt curie.IRI, lexical string) xsd.Value {
+		v, err := xsd.Parse(dt, lexical)
+		it.Then(t).Should(it.Nil(err))
+		return v
+	}
+
+	it.Then(t).Should(
+		it.Equiv(Parse(xsd.XSD_STRING, "a"), xsd.Value(xsd.String("a"))),
+		it.Equiv(Parse(xsd.XSD_ANYURI, "u:a"), xsd.Value(xsd.AnyURI("u:a"))),
+		it.Equiv(Parse(xsd.XSD_INTEGER, "-42"), xsd.Value(xsd.Integer(-42))),
+		it.Equiv(Parse("xsd:int", "42"), xsd.Value(xsd.Integer(42))),
+		it.Equiv(Parse("http://www.w3.org/2001/XMLSchema#integer", "42"), xsd.Value(xsd.Integer(42))),
+		it.Equiv(Parse(xsd.XSD_DECIMAL, "1.5"), xsd.Value(xsd.Decimal(1.5))),
+		it.Equiv(Parse(xsd.XSD_FLOAT, "1.5"), xsd.Value(xsd.Float(1.5))),
+		it.Equiv(Parse(xsd.XSD_DOUBLE, "1e3"), xsd.Value(xsd.Double(1000))),
+		it.Equiv(Parse(xsd.XSD_BOOLEAN, "true"), xsd.Value(xsd.Boolean(true))),
+		it.Equiv(Parse(xsd.XSD_DATE, "2023-01-02"), xsd.Value(xsd.Date(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)))),
+		it.Equiv(Parse(xsd.XSD_DATETIME, "2023-01-02T03:04:05Z"), xsd.Value(xsd.DateTime(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)))),
+		it.Equiv(Parse(xsd.XSD_DURATION, "PT1H30M1.5S"), xsd.Value(xsd.Duration(90*time.Minute+1500*time.Millisecond))),
+		it.Equiv(Parse(xsd.XSD_DURATION, "-P1D"), xsd.Value(xsd.Duration(-24*time.Hour))),
+		it.Equiv(Parse(xsd.XSD_HEXBINARY, "cafe"), xsd.Value(xsd.HexBinary{0xca, 0xfe})),
+		it.Equiv(Parse(xsd.XSD_BASE64BINARY, "yv4="), xsd.Value(xsd.Base64Binary{0xca, 0xfe})),
+		it.Equiv(Parse(XSD_SEMVER, "1.2"), xsd.Value(SemVer{1, 2})),
+	)
+
+	for _, lexical := range []string{"x", "P", "PT1X", "P1H"} {
+		_, err := xsd.Parse(xsd.XSD_DURATION, lexical)
+		it.Then(t).ShouldNot(it.Nil(err))
+	}
+
+	_, err := xsd.Parse(xsd.XSD_INTEGER, "1.5")
+	it.Then(t).ShouldNot(it.Nil(err))
+}
+
+func TestLexical(t *testing.T) {
+	for _, v := range []xsd.Value{
+		xsd.AnyURI("u:a"),
+		xsd.String("a"),
+		xsd.Integer(-42),
+		xsd.Decimal(1.5),
+		xsd.Float(1.5),
+		xsd.Float(math.Inf(1)),
+		xsd.Double(1000),
+		xsd.Double(math.Inf(1)),
+		xsd.Double(math.Inf(-1)),
+		xsd.Boolean(true),
+		xsd.DateTime(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)),
+		xsd.Date(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)),
+		xsd.Duration(90*time.Minute + 1500*time.Millisecond),
+		xsd.HexBinary{0xca, 0xfe},
+		xsd.Base64Binary{0xca, 0xfe},
+		SemVer{1, 2},
+	} {
+		lexical, err := xsd.Lexical(v)
+		it.Then(t).Should(it.Nil(err))
+
+		x, err := xsd.Parse(v.XSDType(), lexical)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equiv(x, v),
+		)
+	}
+
+	lexical, err := xsd.Lexical(xsd.Lang("a", "en"))
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(lexical, "a"),
+	)
+
+	for v, expected := range map[xsd.Value]string{
+		xsd.Float(math.Inf(1)):   "INF",
+		xsd.Float(math.Inf(-1)):  "-INF",
+		xsd.Double(math.Inf(1)):  "INF",
+		xsd.Double(math.Inf(-1)): "-INF",
+		xsd.Double(math.NaN()):   "NaN",
+	} {
+		lexical, err := xsd.Lexical(v)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(lexical, expected),
+		)
+	}
+}
@@ -1,8 +1,12 @@
 package xsd
 
 import (
+	"bytes"
+	"fmt"
+	"math"
 	"reflect"
 	"strings"
+	"time"
 )
 
 func HasPrefix(a, b Value) bool {
@@ -19,11 +23,40 @@ func HasPrefix(a, b Value) bool {
 		}
 
 		return false
+	case LangString:
+		if bv, ok := b.(LangString); ok {
+			return av.Lang == bv.Lang && strings.HasPrefix(av.Value, bv.Value)
+		}
+
+		return false
+	case HexBinary:
+		if bv, ok := b.(HexBinary); ok {
+			return bytes.HasPrefix(av, bv)
+		}
+
+		return false
+	case Base64Binary:
+		if bv, ok := b.(Base64Binary); ok {
+			return bytes.HasPrefix(av, bv)
+		}
+
+		return false
+	}
+
+	if isCustom(a) && isCustom(b) {
+		return hasPrefixCustom(a, b)
 	}
 
 	return false
 }
 
+// Compare defines total order over xsd.Values. Values of same kind are
+// ordered by value, otherwise by kind. Numeric data-types belongs to same
+// kind, they are ordered by numeric value regardless of data-type. Similarly,
+// xsd:date and xsd:dateTime are ordered chronologically. Language-tagged
+// strings are ordered by language tag first, then by value. Binary data-types
+// are ordered byte-wise. User-defined data-types are ordered by registered
+// ordering function.
 func Compare(a, b Value) int {
 	switch av := a.(type) {
 	case AnyURI:
@@ -31,76 +64,284 @@ func Compare(a, b Value) int {
 			return compare(av, bv)
 		}
 
-		return compare(reflect.Kind(1000), typeOf(b))
+		return compare(kindAnyURI, typeOf(b))
 	case String:
 		if bv, ok := b.(String); ok {
 			return compare(av, bv)
 		}
 
 		return compare(reflect.String, typeOf(b))
+	case LangString:
+		if bv, ok := b.(LangString); ok {
+			if c := compare(av.Lang, bv.Lang); c != 0 {
+				return c
+			}
+			return compare(av.Value, bv.Value)
+		}
+
+		return compare(kindLangString, typeOf(b))
+	case Boolean:
+		if bv, ok := b.(Boolean); ok {
+			return compare(boolToInt(av), boolToInt(bv))
+		}
+
+		return compare(reflect.Bool, typeOf(b))
+	case HexBinary, Base64Binary:
+		if typeOf(b) == kindBinary {
+			return compareBinary(av, b)
+		}
+
+		return compare(kindBinary, typeOf(b))
+	case DateTime, Date:
+		if typeOf(b) == reflect.Struct {
+			return compareInstant(av, b)
+		}
+
+		return compare(reflect.Struct, typeOf(b))
+	case Duration:
+		if bv, ok := b.(Duration); ok {
+			return compare(av, bv)
+		}
+
+		return compare(reflect.Int64, typeOf(b))
+	case Integer, Decimal, Float, Double:
+		if typeOf(b) == reflect.Float64 {
+			return compareNumber(av, b)
+		}
+
+		return compare(reflect.Float64, typeOf(b))
 	}
-	return 0
+
+	if typeOf(b) == kindCustom {
+		return compareCustom(a, b)
+	}
+
+	return compare(kindCustom, typeOf(b))
 }
 
-func typeOf(x any) reflect.Kind {
+// SameKind checks that values are comparable by value (e.g. xsd:integer and
+// xsd:double), otherwise the order is defined by kind only.
+func SameKind(a, b Value) bool {
+	ka, kb := typeOf(a), typeOf(b)
+	if ka == kindCustom && kb == kindCustom {
+		return a.XSDType() == b.XSDType()
+	}
+
+	return ka == kb
+}
+
+// kinds of values, which are not defined by reflect.Kind
+const (
+	kindAnyURI     = reflect.Kind(1000)
+	kindLangString = reflect.Kind(1001)
+	kindBinary     = reflect.Kind(1002)
+)
+
+// Validate checks that the value is of built-in or registered data-type,
+// only such values are ordered and encoded by the library.
+func Validate(x Value) error {
+	switch x.(type) {
+	case nil:
+		return fmt.Errorf("xsd value is not defined")
+	case AnyURI, String, LangString, HexBinary, Base64Binary, Boolean,
+		DateTime, Date, Duration, Integer, Decimal, Float, Double:
+		return nil
+	}
+
+	if !isCustom(x) {
+		return fmt.Errorf("xsd data type %s is not registered", x.XSDType())
+	}
+
+	return nil
+}
+
+// it panics on unknown data-types, see Validate
+func typeOf(x Value) reflect.Kind {
 	switch x.(type) {
 	case AnyURI:
-		return reflect.Kind(1000)
+		return kindAnyURI
 	case String:
 		return reflect.String
-	// case string:
-	// 	return reflect.String
-	// case bool:
-	// 	return reflect.Bool
-	// case int:
-	// 	return reflect.Int
-	// case int8:
-	// 	return reflect.Int8
-	// case int16:
-	// 	return reflect.Int16
-	// case int32:
-	// 	return reflect.Int32
-	// case int64:
-	// 	return reflect.Int64
-	// case uint:
-	// 	return reflect.Uint
-	// case uint8:
-	// 	return reflect.Uint8
-	// case uint16:
-	// 	return reflect.Uint16
-	// case uint32:
-	// 	return reflect.Uint32
-	// case uint64:
-	// 	return reflect.Uint64
-	// case float32:
-	// 	return reflect.Float32
-	// case float64:
-	// 	return reflect.Float64
-	// case curie.IRI:
-	// 	return reflect.Kind(1000)
-	// case []byte:
-	// 	return reflect.Kind(1001)
+	case LangString:
+		return kindLangString
+	case HexBinary, Base64Binary:
+		return kindBinary
+	case Boolean:
+		return reflect.Bool
+	case DateTime, Date:
+		return reflect.Struct
+	case Duration:
+		return reflect.Int64
+	case Integer, Decimal, Float, Double:
+		return reflect.Float64
+	default:
+		if isCustom(x) {
+			return kindCustom
+		}
+		panic(fmt.Errorf("xsd data type %T is not supported", x))
+	}
+}
+
+// compare numbers of any numeric data-types. Integers are compared
+// without loss of precision. Numerically equal values of distinct
+// data-types are ordered by data-type so that order remains total.
+func compareNumber(a, b Value) int {
+	if ai, ok := a.(Integer); ok {
+		if bi, ok := b.(Integer); ok {
+			return compare(ai, bi)
+		}
+	}
+
+	af, bf := toFloat(a), toFloat(b)
+	switch {
+	// NaN is less than any number
+	case math.IsNaN(af) && math.IsNaN(bf):
+		return compare(rankOf(a), rankOf(b))
+	case math.IsNaN(af):
+		return -1
+	case math.IsNaN(bf):
+		return 1
+	}
+
+	c := 0
+	ai, aIsInt := a.(Integer)
+	bi, bIsInt := b.(Integer)
+	switch {
+	case aIsInt:
+		c = compareIntFloat(ai, bf)
+	case bIsInt:
+		c = -compareIntFloat(bi, af)
+	default:
+		c = compare(af, bf)
+	}
+
+	if c != 0 {
+		return c
+	}
+
+	return compare(rankOf(a), rankOf(b))
+}
+
+// compare integer and float without loss of precision, integers beyond 2^53
+// are not representable by float. The float is compared by its integral
+// part, the fraction resolves the tie.
+func compareIntFloat(i Integer, f float64) int {
+	switch {
+	case f >= math.MaxInt64:
+		return -1
+	case f < math.MinInt64:
+		return 1
+	}
+
+	t := math.Trunc(f)
+	if c := compare(int64(i), int64(t)); c != 0 {
+		return c
+	}
+
+	return compare(t, f)
+}
+
+// compare binary values byte-wise, equal bytes of distinct data-types are
+// ordered by data-type.
+func compareBinary(a, b Value) int {
+	if c := bytes.Compare(bytesOf(a), bytesOf(b)); c != 0 {
+		return c
+	}
+
+	_, aIsHex := a.(HexBinary)
+	_, bIsHex := b.(HexBinary)
+	switch {
+	case aIsHex && !bIsHex:
+		return -1
+	case !aIsHex && bIsHex:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func bytesOf(x Value) []byte {
+	switch v := x.(type) {
+	case HexBinary:
+		return v
+	case Base64Binary:
+		return v
 	default:
-		panic("not supported")
-		// return reflect.Invalid
+		return nil
 	}
 }
 
-// func value[T comparable](x Value) T {
-// 	switch av := x.(type) {
-// 	case AnyURI:
-// 	case String:
-// 		return av.Value
-// 	}
-// }
-
-// func compareIt[T Value](t reflect.Kind, a T, b any) int {
-// 	if bv, ok := b.(T); ok {
-// 		return compare(a, bv)
-// 	} else {
-// 		return compare(t, typeOf(b))
-// 	}
-// }
+// compare instants of time, the date is an instant at the beginning of the day (UTC).
+// Same instants of distinct data-types are ordered by data-type.
+func compareInstant(a, b Value) int {
+	at, bt := instantOf(a), instantOf(b)
+	switch {
+	case at.Before(bt):
+		return -1
+	case at.After(bt):
+		return 1
+	}
+
+	_, aIsDate := a.(Date)
+	_, bIsDate := b.(Date)
+	switch {
+	case aIsDate && !bIsDate:
+		return -1
+	case !aIsDate && bIsDate:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func instantOf(x Value) time.Time {
+	switch v := x.(type) {
+	case DateTime:
+		return time.Time(v)
+	case Date:
+		y, m, d := time.Time(v).Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Time{}
+	}
+}
+
+func boolToInt(x Boolean) int {
+	if x {
+		return 1
+	}
+	return 0
+}
+
+func toFloat(x Value) float64 {
+	switch v := x.(type) {
+	case Integer:
+		return float64(v)
+	case Decimal:
+		return float64(v)
+	case Float:
+		return float64(v)
+	case Double:
+		return float64(v)
+	default:
+		return math.NaN()
+	}
+}
+
+func rankOf(x Value) int {
+	switch x.(type) {
+	case Integer:
+		return 1
+	case Decimal:
+		return 2
+	case Float:
+		return 3
+	case Double:
+		return 4
+	default:
+		return 0
+	}
+}
 
 func compare[T interface {
 	~string |
@@ -117,10 +358,3 @@ func compare[T interface {
 		return 0
 	}
 }
-
-// type Comparable interface {
-// 	~string |
-// 		~int | ~int8 | ~int16 | ~int32 | ~int64 |
-// 		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
-// 		~float32 | ~float64
-// }
@@ -0,0 +1,153 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package xsd_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer/xsd"
+	"github.com/fogfish/it/v2"
+)
+
+func TestFrom(t *testing.T) {
+	type Age uint8
+
+	it.Then(t).Should(
+		it.Equal(xsd.From(curie.IRI("a:b")), xsd.Value(xsd.AnyURI("a:b"))),
+		it.Equal(xsd.From("a"), xsd.Value(xsd.String("a"))),
+		it.Equal(xsd.From(10), xsd.Value(xsd.Integer(10))),
+		it.Equal(xsd.From(uint16(10)), xsd.Value(xsd.Integer(10))),
+		it.Equal(xsd.From(Age(10)), xsd.Value(xsd.Integer(10))),
+		it.Equal(xsd.From(float32(1.5)), xsd.Value(xsd.Float(1.5))),
+		it.Equal(xsd.From(1.5), xsd.Value(xsd.Double(1.5))),
+		it.Equal(xsd.From(xsd.Decimal(1.5)), xsd.Value(xsd.Decimal(1.5))),
+	)
+
+	// unsigned integers beyond int64 do not wrap around
+	type Big uint64
+	it.Then(t).Should(
+		it.Equal(xsd.From(uint64(math.MaxInt64)), xsd.Value(xsd.Integer(math.MaxInt64))),
+		it.Equal(xsd.From(uint64(math.MaxUint64)), xsd.Value(xsd.Decimal(math.MaxUint64))),
+		it.Equal(xsd.From(Big(math.MaxUint64)), xsd.Value(xsd.Decimal(math.MaxUint64))),
+		it.Equal(xsd.Compare(xsd.From(uint64(math.MaxUint64)), xsd.Integer(0)), 1),
+	)
+}
+
+func TestCompareNumbers(t *testing.T) {
+	it.Then(t).Should(
+		it.Equal(xsd.Compare(xsd.Integer(1), xsd.Integer(2)), -1),
+		it.Equal(xsd.Compare(xsd.Integer(2), xsd.Integer(2)), 0),
+		it.Equal(xsd.Compare(xsd.Integer(3), xsd.Integer(2)), 1),
+		it.Equal(xsd.Compare(xsd.Integer(1), xsd.Double(1.5)), -1),
+		it.Equal(xsd.Compare(xsd.Double(1.5), xsd.Integer(1)), 1),
+		it.Equal(xsd.Compare(xsd.Decimal(2.5), xsd.Float(2.0)), 1),
+		it.Equal(xsd.Compare(xsd.Integer(-10), xsd.Decimal(-9.5)), -1),
+	)
+
+	// numerically equal values of distinct types are ordered by type
+	it.Then(t).Should(
+		it.Equal(xsd.Compare(xsd.Integer(1), xsd.Double(1.0)), -1),
+		it.Equal(xsd.Compare(xsd.Double(1.0), xsd.Integer(1)), 1),
+	)
+
+	// integers beyond 2^53 are compared with floats exactly
+	it.Then(t).Should(
+		it.Equal(xsd.Compare(xsd.Integer(1<<53+1), xsd.Double(1<<53)), 1),
+		it.Equal(xsd.Compare(xsd.Double(1<<53), xsd.Integer(1<<53+1)), -1),
+		it.Equal(xsd.Compare(xsd.Integer(1<<53-1), xsd.Decimal(1<<53)), -1),
+		it.Equal(xsd.Compare(xsd.Integer(math.MaxInt64), xsd.Double(math.MaxInt64)), -1),
+		it.Equal(xsd.Compare(xsd.Integer(math.MinInt64), xsd.Double(math.MinInt64)), -1),
+		it.Equal(xsd.Compare(xsd.Integer(math.MinInt64), xsd.Double(-math.MaxFloat64)), 1),
+		it.Equal(xsd.Compare(xsd.Integer(-2), xsd.Double(-1.5)), -1),
+		it.Equal(xsd.Compare(xsd.Integer(-1), xsd.Double(-1.5)), 1),
+	)
+}
+
+func TestCompareKinds(t *testing.T) {
+	it.Then(t).Should(
+		it.Equal(xsd.Compare(xsd.Integer(1000), xsd.String("a")), -1),
+		it.Equal(xsd.Compare(xsd.String("a"), xsd.Double(1.0)), 1),
+		it.Equal(xsd.Compare(xsd.AnyURI("a:b"), xsd.Integer(1)), 1),
+		it.True(xsd.SameKind(xsd.Integer(1), xsd.Double(1.0))),
+		it.True(!xsd.SameKind(xsd.Integer(1), xsd.String("1"))),
+	)
+}
+
+func TestCompareBoolean(t *testing.T) {
+	it.Then(t).Should(
+		it.Equal(xsd.From(true), xsd.Value(xsd.Boolean(true))),
+		it.Equal(xsd.Compare(xsd.Boolean(false), xsd.Boolean(true)), -1),
+		it.Equal(xsd.Compare(xsd.Boolean(true), xsd.Boolean(true)), 0),
+		it.Equal(xsd.Compare(xsd.Boolean(true), xsd.Integer(0)), -1),
+		it.Equal(xsd.Compare(xsd.String("a"), xsd.Boolean(false)), 1),
+	)
+}
+
+func TestCompareTemporal(t *testing.T) {
+	t0 := time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	it.Then(t).Should(
+		it.Equal(xsd.From(t0), xsd.Value(xsd.DateTime(t0))),
+		it.Equal(xsd.From(time.Second), xsd.Value(xsd.Duration(time.Second))),
+		it.Equal(xsd.Compare(xsd.DateTime(t0), xsd.DateTime(t1)), -1),
+		it.Equal(xsd.Compare(xsd.DateTime(t1), xsd.DateTime(t0)), 1),
+		it.Equal(xsd.Compare(xsd.DateTime(t0), xsd.DateTime(t0.In(time.FixedZone("X", 3600)))), 0),
+		it.Equal(xsd.Compare(xsd.Date(t0), xsd.DateTime(t0)), -1),
+		it.Equal(xsd.Compare(xsd.Date(t0), xsd.Date(t1)), 0),
+		it.Equal(xsd.Compare(xsd.Date(t0.AddDate(0, 0, 1)), xsd.DateTime(t1)), 1),
+		it.Equal(xsd.Compare(xsd.Duration(time.Second), xsd.Duration(time.Minute)), -1),
+		it.Equal(xsd.Compare(xsd.Duration(time.Second), xsd.Integer(1)), -1),
+		it.True(!xsd.SameKind(xsd.DateTime(t0), xsd.String("a"))),
+	)
+}
+
+func TestStringTemporal(t *testing.T) {
+	t0 := time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	it.Then(t).Should(
+		it.Equal(xsd.DateTime(t0).String(), "2023-05-06T07:08:09Z"),
+		it.Equal(xsd.Date(t0).String(), "2023-05-06"),
+		it.Equal(xsd.Duration(90*time.Minute+1500*time.Millisecond).String(), "PT1H30M1.5S"),
+		it.Equal(xsd.Duration(-time.Second).String(), "-PT1S"),
+	)
+}
+
+func TestCompareLangString(t *testing.T) {
+	it.Then(t).Should(
+		it.Equal(xsd.Lang("a", "EN"), xsd.LangString{Value: "a", Lang: "en"}),
+		it.Equal(xsd.Compare(xsd.Lang("b", "de"), xsd.Lang("a", "en")), -1),
+		it.Equal(xsd.Compare(xsd.Lang("a", "en"), xsd.Lang("b", "en")), -1),
+		it.Equal(xsd.Compare(xsd.Lang("a", "en"), xsd.Lang("a", "en")), 0),
+		it.Equal(xsd.Compare(xsd.Lang("a", "en"), xsd.String("a")), 1),
+		it.True(xsd.HasPrefix(xsd.Lang("abc", "en"), xsd.Lang("", "en"))),
+		it.True(xsd.HasPrefix(xsd.Lang("abc", "en"), xsd.Lang("ab", "en"))),
+		it.True(!xsd.HasPrefix(xsd.Lang("abc", "en-gb"), xsd.Lang("", "en"))),
+		it.True(!xsd.HasPrefix(xsd.String("abc"), xsd.Lang("", "en"))),
+	)
+}
+
+func TestCompareBinary(t *testing.T) {
+	it.Then(t).Should(
+		it.Equiv(xsd.From([]byte{1, 2}), xsd.Value(xsd.Base64Binary{1, 2})),
+		it.Equiv(xsd.From(xsd.HexBinary{1, 2}), xsd.Value(xsd.HexBinary{1, 2})),
+		it.Equal(xsd.Compare(xsd.HexBinary{1, 2}, xsd.HexBinary{1, 3}), -1),
+		it.Equal(xsd.Compare(xsd.HexBinary{1, 2}, xsd.HexBinary{1}), 1),
+		it.Equal(xsd.Compare(xsd.HexBinary{1, 2}, xsd.Base64Binary{1, 2}), -1),
+		it.Equal(xsd.Compare(xsd.Base64Binary{1, 2}, xsd.Base64Binary{1, 2}), 0),
+		it.Equal(xsd.Compare(xsd.Base64Binary{1, 2}, xsd.String("a")), 1),
+		it.True(xsd.HasPrefix(xsd.HexBinary{1, 2, 3}, xsd.HexBinary{1, 2})),
+		it.True(!xsd.HasPrefix(xsd.HexBinary{1, 2, 3}, xsd.HexBinary{2})),
+		it.Equal(xsd.HexBinary{0xca, 0xfe}.String(), "cafe"),
+		it.Equal(xsd.Base64Binary("hi").String(), "aGk="),
+	)
+}
@@ -0,0 +1,116 @@
+package xsd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/fogfish/curie"
+)
+
+// The registry of user-defined data-types (e.g. geo points, money, etc).
+// The library dispatches ordering and encoding of user-defined values
+// through the registry using the data-type IRI (Value.XSDType()).
+var registry = struct {
+	sync.RWMutex
+	types map[curie.IRI]*datatype
+}{
+	types: map[curie.IRI]*datatype{},
+}
+
+type datatype struct {
+	compare func(a, b Value) int
+	encode  func(Value) string
+	decode  func(string) (Value, error)
+}
+
+// kind of user-defined values, it follows all built-in data-types
+const kindCustom = reflect.Kind(2000)
+
+// Register user-defined data-type. The type T is identified by IRI, which
+// must be returned by T.XSDType(). The ordering function defines total order
+// of values, it returns negative, zero or positive number like strings.Compare.
+// The string codec is used by storage backends. The string codec
+// must preserve prefixes if prefix queries are used with the data-type.
+func Register[T Value](
+	dt curie.IRI,
+	compare func(a, b T) int,
+	encode func(T) string,
+	decode func(string) (T, error),
+) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.types[dt] = &datatype{
+		compare: func(a, b Value) int { return compare(a.(T), b.(T)) },
+		encode:  func(x Value) string { return encode(x.(T)) },
+		decode: func(s string) (Value, error) {
+			x, err := decode(s)
+			if err != nil {
+				return nil, err
+			}
+			return x, nil
+		},
+	}
+}
+
+// Encode user-defined value to string using the registered codec
+func Encode(x Value) (string, error) {
+	dt, has := lookup(x.XSDType())
+	if !has {
+		return "", fmt.Errorf("xsd data type %s is not registered", x.XSDType())
+	}
+
+	return dt.encode(x), nil
+}
+
+// Decode user-defined value from string using the registered codec
+func Decode(iri curie.IRI, val string) (Value, error) {
+	dt, has := lookup(iri)
+	if !has {
+		return nil, fmt.Errorf("xsd data type %s is not registered", iri)
+	}
+
+	return dt.decode(val)
+}
+
+func lookup(iri curie.IRI) (*datatype, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	dt, has := registry.types[iri]
+	return dt, has
+}
+
+func isCustom(x Value) bool {
+	_, has := lookup(x.XSDType())
+	return has
+}
+
+// compare user-defined values, values of distinct data-types are
+// ordered by data-type IRI
+func compareCustom(a, b Value) int {
+	if a.XSDType() != b.XSDType() {
+		return compare(a.XSDType(), b.XSDType())
+	}
+
+	dt, _ := lookup(a.XSDType())
+	switch c := dt.compare(a, b); {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func hasPrefixCustom(a, b Value) bool {
+	if a.XSDType() != b.XSDType() {
+		return false
+	}
+
+	dt, _ := lookup(a.XSDType())
+	return strings.HasPrefix(dt.encode(a), dt.encode(b))
+}
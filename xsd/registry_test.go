@@ -0,0 +1,96 @@
+//
+// Copyright (C) 2022 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/hexagon
+//
+
+package xsd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fogfish/curie"
+	"github.com/fogfish/hexer/xsd"
+	"github.com/fogfish/it/v2"
+)
+
+type SemVer struct{ Major, Minor int }
+
+const XSD_SEMVER = curie.IRI("ex:semver")
+
+func (SemVer) XSDType() curie.IRI { return XSD_SEMVER }
+
+func init() {
+	xsd.Register(XSD_SEMVER,
+		func(a, b SemVer) int {
+			switch {
+			case a.Major != b.Major:
+				return a.Major - b.Major
+			default:
+				return a.Minor - b.Minor
+			}
+		},
+		func(v SemVer) string { return fmt.Sprintf("%d.%d", v.Major, v.Minor) },
+		func(s string) (v SemVer, err error) {
+			_, err = fmt.Sscanf(s, "%d.%d", &v.Major, &v.Minor)
+			return
+		},
+	)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("Compare", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Equal(xsd.Compare(SemVer{1, 2}, SemVer{1, 10}), -1),
+			it.Equal(xsd.Compare(SemVer{1, 2}, SemVer{1, 2}), 0),
+			it.Equal(xsd.Compare(SemVer{2, 0}, SemVer{1, 10}), 1),
+			it.Equal(xsd.Compare(SemVer{1, 2}, xsd.AnyURI("a:b")), 1),
+			it.Equal(xsd.Compare(xsd.String("a"), SemVer{1, 2}), -1),
+			it.True(xsd.SameKind(SemVer{1, 2}, SemVer{2, 3})),
+			it.True(!xsd.SameKind(SemVer{1, 2}, xsd.Integer(1))),
+		)
+	})
+
+	t.Run("HasPrefix", func(t *testing.T) {
+		it.Then(t).Should(
+			it.True(xsd.HasPrefix(SemVer{1, 20}, SemVer{1, 2})),
+			it.True(!xsd.HasPrefix(SemVer{1, 20}, xsd.String("1"))),
+		)
+	})
+
+	t.Run("Codec", func(t *testing.T) {
+		val, err := xsd.Encode(SemVer{1, 2})
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(val, "1.2"),
+		)
+
+		v, err := xsd.Decode(XSD_SEMVER, "3.4")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(v, xsd.Value(SemVer{3, 4})),
+		)
+	})
+
+	t.Run("NotRegistered", func(t *testing.T) {
+		_, err := xsd.Decode("ex:unknown", "1")
+		it.Then(t).ShouldNot(it.Nil(err))
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Nil(xsd.Validate(xsd.Integer(1))),
+			it.Nil(xsd.Validate(SemVer{1, 2})),
+			it.Fail(func() error { return xsd.Validate(Unknown{}) }).Contain("not registered"),
+			it.Fail(func() error { return xsd.Validate(nil) }).Contain("not defined"),
+		)
+	})
+}
+
+// data-type which is not registered
+type Unknown struct{}
+
+func (Unknown) XSDType() curie.IRI { return "ex:unknown" }
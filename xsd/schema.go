@@ -1,6 +1,13 @@
 package xsd
 
-import "github.com/fogfish/curie"
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/fogfish/curie"
+)
 
 // DataType is a type constrain used by the library.
 // See https://www.w3.org/TR/xmlschema-2/#datatype
@@ -18,20 +25,11 @@ type DataType interface {
 		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
 		~float32 | ~float64 |
 		~bool |
-		~[]byte
+		~[]byte |
+		time.Time | DateTime | Date |
+		LangString
 }
 
-// The floating point data-type in knowledge statement.
-// The library uses various uint precisions.
-type Float = float32
-type Double = float64
-
-// The boolean data-type in knowledge statement
-type Boolean = bool
-
-type HexBinary = []byte
-type Base64Binary = []byte
-
 // From builds Object from Golang type
 func From[T DataType](value T) Value {
 	switch v := any(value).(type) {
@@ -43,9 +41,95 @@ func From[T DataType](value T) Value {
 		return String(v)
 	case String:
 		return v
-	// case int:
-	// 	return Integer{Value: v}
+	case LangString:
+		return v
+	case Integer:
+		return v
+	case Decimal:
+		return v
+	case Float:
+		return v
+	case Double:
+		return v
+	case DateTime:
+		return v
+	case Date:
+		return v
+	case Duration:
+		return v
+	case time.Time:
+		return DateTime(v)
+	case time.Duration:
+		return Duration(v)
+	case HexBinary:
+		return v
+	case Base64Binary:
+		return v
+	case []byte:
+		return Base64Binary(v)
+	case Boolean:
+		return v
+	case bool:
+		return Boolean(v)
+	case int:
+		return Integer(v)
+	case int8:
+		return Integer(v)
+	case int16:
+		return Integer(v)
+	case int32:
+		return Integer(v)
+	case int64:
+		return Integer(v)
+	case uint:
+		return fromUint(uint64(v))
+	case uint8:
+		return Integer(v)
+	case uint16:
+		return Integer(v)
+	case uint32:
+		return Integer(v)
+	case uint64:
+		return fromUint(v)
+	case float32:
+		return Float(v)
+	case float64:
+		return Double(v)
+	default:
+		return fromKind(reflect.ValueOf(value))
+	}
+}
+
+// unsigned integers beyond int64 do not fit xsd:integer, they are decimals.
+// The precision of decimal is limited, see Decimal.
+func fromUint(v uint64) Value {
+	if v > math.MaxInt64 {
+		return Decimal(v)
+	}
+	return Integer(v)
+}
+
+// builds Object from user-defined types (e.g. type Age int)
+func fromKind(v reflect.Value) Value {
+	switch v.Kind() {
+	case reflect.String:
+		return String(v.String())
+	case reflect.Bool:
+		return Boolean(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Integer(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fromUint(v.Uint())
+	case reflect.Float32:
+		return Float(v.Float())
+	case reflect.Float64:
+		return Double(v.Float())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return Base64Binary(v.Bytes())
+		}
+		panic(fmt.Errorf("xsd data type %T is not supported", v.Interface()))
 	default:
-		panic("xxxx")
+		panic(fmt.Errorf("xsd data type %T is not supported", v.Interface()))
 	}
 }
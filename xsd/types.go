@@ -1,7 +1,11 @@
 package xsd
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fogfish/curie"
 )
@@ -18,7 +22,7 @@ func (v AnyURI) XSDType() curie.IRI { return XSD_ANYURI }
 func (v AnyURI) String() string     { return curie.IRI(v).Safe() }
 
 // The string data-type represents character strings in knowledge statements.
-// The language strings are annotated with corresponding language tag.
+// The language strings are annotated with language tag, see LangString.
 type String string
 
 const XSD_STRING = curie.IRI("xsd:string")
@@ -26,21 +30,137 @@ const XSD_STRING = curie.IRI("xsd:string")
 func (v String) XSDType() curie.IRI { return XSD_STRING }
 func (v String) String() string     { return strconv.Quote(string(v)) }
 
+// The language-tagged string data-type represents human readable text
+// annotated with BCP47 language tag (e.g. "en", "en-gb").
+type LangString struct {
+	Value string
+	Lang  string
+}
+
+const RDF_LANGSTRING = curie.IRI("rdf:langString")
+
+// Lang builds language-tagged string, the tag is normalized to lower case
+func Lang(value, tag string) LangString {
+	return LangString{Value: value, Lang: strings.ToLower(tag)}
+}
+
+func (v LangString) XSDType() curie.IRI { return RDF_LANGSTRING }
+func (v LangString) String() string     { return strconv.Quote(v.Value) + "@" + v.Lang }
+
 // The Integer data-type in knowledge statement.
-// The library uses various int precision data-types to represent decimal values.
-// type XSDInteger = int
-// type Byte = int8
-// type Short = int16
-// type Int = int32
-// type Long = int64
-// type NonNegativeInteger = uint
-// type UnsignedByte = uint8
-// type UnsignedShort = uint16
-// type UnsignedInt = uint32
-// type UnsignedLong = uint64
-
-// const XSD_INTEGER = curie.IRI("xsd:integer")
-
-// type Integer struct{ Value XSDInteger }
-
-// func (Integer) XSDType() curie.IRI { return XSD_INTEGER }
+// The library uses int64 to represent any Golang integral type (int, uint, ...).
+type Integer int64
+
+const XSD_INTEGER = curie.IRI("xsd:integer")
+
+func (v Integer) XSDType() curie.IRI { return XSD_INTEGER }
+func (v Integer) String() string     { return strconv.FormatInt(int64(v), 10) }
+
+// The Decimal data-type in knowledge statement.
+// The library approximates arbitrary precision decimals with float64,
+// the precision is limited to 53 bits of significand: decimals, which differ
+// beyond 15-17 significant digits (e.g. unsigned integers beyond int64),
+// are same value.
+type Decimal float64
+
+const XSD_DECIMAL = curie.IRI("xsd:decimal")
+
+func (v Decimal) XSDType() curie.IRI { return XSD_DECIMAL }
+func (v Decimal) String() string     { return strconv.FormatFloat(float64(v), 'f', -1, 64) }
+
+// The floating point data-type in knowledge statement (single precision).
+type Float float32
+
+const XSD_FLOAT = curie.IRI("xsd:float")
+
+func (v Float) XSDType() curie.IRI { return XSD_FLOAT }
+func (v Float) String() string     { return strconv.FormatFloat(float64(v), 'g', -1, 32) }
+
+// The floating point data-type in knowledge statement (double precision).
+type Double float64
+
+const XSD_DOUBLE = curie.IRI("xsd:double")
+
+func (v Double) XSDType() curie.IRI { return XSD_DOUBLE }
+func (v Double) String() string     { return strconv.FormatFloat(float64(v), 'g', -1, 64) }
+
+// The boolean data-type in knowledge statement
+type Boolean bool
+
+const XSD_BOOLEAN = curie.IRI("xsd:boolean")
+
+func (v Boolean) XSDType() curie.IRI { return XSD_BOOLEAN }
+func (v Boolean) String() string     { return strconv.FormatBool(bool(v)) }
+
+// The dateTime data-type in knowledge statement represents an instant of time.
+type DateTime time.Time
+
+const XSD_DATETIME = curie.IRI("xsd:dateTime")
+
+func (v DateTime) XSDType() curie.IRI { return XSD_DATETIME }
+func (v DateTime) String() string     { return time.Time(v).Format(time.RFC3339Nano) }
+
+// The date data-type in knowledge statement represents a calendar day,
+// the time of the day is ignored.
+type Date time.Time
+
+const XSD_DATE = curie.IRI("xsd:date")
+
+func (v Date) XSDType() curie.IRI { return XSD_DATE }
+func (v Date) String() string     { return time.Time(v).Format(time.DateOnly) }
+
+// The duration data-type in knowledge statement represents an interval of time.
+type Duration time.Duration
+
+const XSD_DURATION = curie.IRI("xsd:duration")
+
+func (v Duration) XSDType() curie.IRI { return XSD_DURATION }
+
+// String formats duration using ISO 8601 notation (e.g. PT1H30M)
+func (v Duration) String() string {
+	d := time.Duration(v)
+	if d == 0 {
+		return "PT0S"
+	}
+
+	sb := strings.Builder{}
+	if d < 0 {
+		sb.WriteString("-")
+		d = -d
+	}
+	sb.WriteString("PT")
+
+	if h := d / time.Hour; h > 0 {
+		sb.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+		d -= h * time.Hour
+	}
+
+	if m := d / time.Minute; m > 0 {
+		sb.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+		d -= m * time.Minute
+	}
+
+	if d > 0 {
+		sb.WriteString(strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S")
+	}
+
+	return sb.String()
+}
+
+// The binary data-type in knowledge statement, the value is hex-encoded
+// when it is represented as string.
+type HexBinary []byte
+
+const XSD_HEXBINARY = curie.IRI("xsd:hexBinary")
+
+func (v HexBinary) XSDType() curie.IRI { return XSD_HEXBINARY }
+func (v HexBinary) String() string     { return hex.EncodeToString(v) }
+
+// The binary data-type in knowledge statement, the value is base64-encoded
+// when it is represented as string.
+type Base64Binary []byte
+
+const XSD_BASE64BINARY = curie.IRI("xsd:base64Binary")
+
+func (v Base64Binary) XSDType() curie.IRI { return XSD_BASE64BINARY }
+func (v Base64Binary) String() string     { return base64.StdEncoding.EncodeToString(v) }